@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Start Starts the admin API and metrics on their own port. API requests must be authenticated with the configured bearer token.
+// Metrics are served even when no token is configured, in which case every API request is refused.
+func Start() {
+	if config.Get().Admin.Port <= 0 {
+		return
+	}
+
+	if config.Get().Admin.Token == "" {
+		log.Println("No admin token is configured, so only metrics will be served until one is set")
+	}
+
+	log.Printf("Starting admin API on port: %v\n", config.Get().Admin.Port)
+
+	err := http.ListenAndServe(fmt.Sprintf(":%v", config.Get().Admin.Port), newServeMux())
+
+	if err != nil {
+		log.Printf("Admin API stopped - %v\n", err)
+	}
+}
+
+// Creates the mux for the admin listener. Metrics are served without authentication so they can be scraped.
+func newServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("/", authenticate(newRouter()))
+
+	return mux
+}
+
+// Creates the router with all admin API routes
+func newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /users", handleGetUsers)
+	mux.HandleFunc("POST /users/{id}/kick", handleKickUser)
+	mux.HandleFunc("POST /users/{id}/mute", handleMuteUser)
+	mux.HandleFunc("POST /users/{id}/notify", handleNotifyUser)
+	mux.HandleFunc("GET /games", handleGetGames)
+	mux.HandleFunc("POST /games/{id}/disband", handleDisbandGame)
+	mux.HandleFunc("GET /channels", handleGetChannels)
+	mux.HandleFunc("GET /spectators", handleGetSpectators)
+	mux.HandleFunc("POST /announcements", handleAnnouncement)
+	mux.HandleFunc("GET /maintenance", handleGetMaintenance)
+	mux.HandleFunc("PUT /maintenance", handleSetMaintenance)
+	mux.HandleFunc("GET /activity", handleGetActivity)
+
+	return mux
+}
+
+// Rejects requests that don't have the admin token in their Authorization header, and all requests if there is no token
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := config.Get().Admin.Token
+
+		if expected == "" {
+			writeError(w, http.StatusServiceUnavailable, "no admin token is configured")
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Failed to write admin API response - %v\n", err)
+	}
+}
+
+// Writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// Decodes a JSON request body
+func readJSON(r *http.Request, data interface{}) error {
+	return json.NewDecoder(r.Body).Decode(data)
+}
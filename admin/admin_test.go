@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/sessions"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	previous := config.Get()
+	config.Set(&config.Configuration{})
+	config.Get().Admin.Token = "secret"
+	defer func() { config.Set(previous) }()
+
+	handler := authenticate(newRouter())
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{name: "missing token", authorization: "", expected: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer wrong", expected: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "secret", expected: http.StatusUnauthorized},
+		{name: "valid token", authorization: "Bearer secret", expected: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/spectators", nil)
+
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.expected {
+				t.Fatalf("expected status %v, got %v", test.expected, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMetricsAreNotAuthenticated(t *testing.T) {
+	previous := config.Get()
+	config.Set(&config.Configuration{})
+	config.Get().Admin.Token = "secret"
+	defer func() { config.Set(previous) }()
+
+	rec := httptest.NewRecorder()
+	newServeMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v", http.StatusOK, rec.Code)
+	}
+
+	if !strings.Contains(rec.Body.String(), "z_online_users") {
+		t.Fatal("expected metrics to be served")
+	}
+
+	rec = httptest.NewRecorder()
+	newServeMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %v, got %v", http.StatusUnauthorized, rec.Code)
+	}
+}
+
+func TestWithoutToken(t *testing.T) {
+	previous := config.Get()
+	config.Set(&config.Configuration{})
+	defer func() { config.Set(previous) }()
+
+	rec := httptest.NewRecorder()
+	newServeMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected metrics to be served without a token, got status %v", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Authorization", "Bearer ")
+
+	rec = httptest.NewRecorder()
+	newServeMux().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %v, got %v", http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+func TestSetMaintenance(t *testing.T) {
+	previous := config.Get()
+	config.Set(&config.Configuration{})
+	config.Get().Admin.Token = "secret"
+	defer func() { config.Set(previous) }()
+
+	defer sessions.SetMaintenanceMode(false)
+
+	req := httptest.NewRequest(http.MethodPut, "/maintenance", strings.NewReader(`{"enabled": true}`))
+	req.Header.Set("Authorization", "Bearer secret")
+
+	rec := httptest.NewRecorder()
+	newServeMux().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v", http.StatusOK, rec.Code)
+	}
+
+	if !sessions.IsMaintenanceEnabled() {
+		t.Fatal("expected maintenance mode to be enabled")
+	}
+}
@@ -0,0 +1,293 @@
+package admin
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/sessions"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type onlineUser struct {
+	Id                 int                   `json:"id"`
+	Username           string                `json:"username"`
+	Country            string                `json:"country"`
+	Status             *objects.ClientStatus `json:"status"`
+	MultiplayerGameId  int                   `json:"multiplayer_game_id"`
+	OutboundQueueDepth int                   `json:"outbound_queue_depth"`
+	Playtime           map[string]int64      `json:"playtime"`
+}
+
+type chatChannel struct {
+	Name         string `json:"name"`
+	Type         int    `json:"type"`
+	Description  string `json:"description"`
+	AdminOnly    bool   `json:"admin_only"`
+	Participants int    `json:"participants"`
+}
+
+type spectatorNode struct {
+	UserId     int   `json:"user_id"`
+	Spectators []int `json:"spectators"`
+}
+
+type messageRequest struct {
+	Message string `json:"message"`
+}
+
+type muteRequest struct {
+	Seconds int `json:"seconds"`
+}
+
+type maintenanceStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Returns every online user
+func handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	users := make([]onlineUser, 0)
+
+	for _, user := range sessions.GetOnlineUsers() {
+		users = append(users, onlineUser{
+			Id:                 user.Info.Id,
+			Username:           user.Info.Username,
+			Country:            user.Info.Country,
+			Status:             user.GetClientStatus(),
+			MultiplayerGameId:  user.GetMultiplayerGameId(),
+			OutboundQueueDepth: user.GetOutboundQueueDepth(),
+			Playtime:           getSessionPlaytime(user),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+// Kicks an online user from the server
+func handleKickUser(w http.ResponseWriter, r *http.Request) {
+	user := getOnlineUserFromPath(w, r)
+
+	if user == nil {
+		return
+	}
+
+	if user == chat.Bot {
+		writeError(w, http.StatusBadRequest, "the bot cannot be kicked")
+		return
+	}
+
+	chat.KickUser(user)
+	log.Printf("[Admin API] Kicked %v (#%v)\n", user.Info.Username, user.Info.Id)
+	writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("%v has been kicked from the server.", user.Info.Username)})
+}
+
+// Mutes a user for a given amount of seconds. Muting for zero seconds unmutes the user.
+func handleMuteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	var body muteRequest
+
+	if err := readJSON(r, &body); err != nil || body.Seconds < 0 {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := db.GetUserById(id)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	if err := chat.MuteUser(user, time.Duration(body.Seconds)*time.Second); err != nil {
+		log.Printf("[Admin API] Failed to mute user #%v - %v\n", id, err)
+		writeError(w, http.StatusInternalServerError, "failed to mute user")
+		return
+	}
+
+	log.Printf("[Admin API] Muted %v (#%v) for %v seconds\n", user.Username, user.Id, body.Seconds)
+	writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("%v has been muted for %v seconds.", user.Username, body.Seconds)})
+}
+
+// Sends a notification to an online user
+func handleNotifyUser(w http.ResponseWriter, r *http.Request) {
+	user := getOnlineUserFromPath(w, r)
+
+	if user == nil {
+		return
+	}
+
+	var body messageRequest
+
+	if err := readJSON(r, &body); err != nil || body.Message == "" {
+		writeError(w, http.StatusBadRequest, "a message is required")
+		return
+	}
+
+	chat.NotifyUser(user, body.Message)
+	writeJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Your notification has been sent to: %v.", user.Info.Username)})
+}
+
+// Returns every multiplayer game
+func handleGetGames(w http.ResponseWriter, r *http.Request) {
+	games := make([]json.RawMessage, 0)
+
+	for _, game := range multiplayer.GetGames() {
+		var data []byte
+		var err error
+
+		game.RunLocked(func() {
+			data, err = json.Marshal(game.Data)
+		})
+
+		if err != nil {
+			continue
+		}
+
+		games = append(games, data)
+	}
+
+	writeJSON(w, http.StatusOK, games)
+}
+
+// Disbands a multiplayer game
+func handleDisbandGame(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid game id")
+		return
+	}
+
+	game := multiplayer.GetGameById(id)
+
+	if game == nil {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+
+	game.RunLocked(func() {
+		game.Disband()
+	})
+
+	log.Printf("[Admin API] Disbanded multiplayer game #%v\n", id)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "The game has been disbanded."})
+}
+
+// Returns every chat channel
+func handleGetChannels(w http.ResponseWriter, r *http.Request) {
+	channels := make([]chatChannel, 0)
+
+	for _, channel := range chat.GetAllChannels() {
+		channels = append(channels, chatChannel{
+			Name:         channel.Name,
+			Type:         int(channel.Type),
+			Description:  channel.Description,
+			AdminOnly:    channel.AdminOnly,
+			Participants: channel.GetParticipantCount(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, channels)
+}
+
+// Returns who is spectating who for every online user that has spectators
+func handleGetSpectators(w http.ResponseWriter, r *http.Request) {
+	graph := make([]spectatorNode, 0)
+
+	for _, user := range sessions.GetOnlineUsers() {
+		spectators := user.GetSpectators()
+
+		if len(spectators) == 0 {
+			continue
+		}
+
+		node := spectatorNode{UserId: user.Info.Id, Spectators: make([]int, 0, len(spectators))}
+
+		for _, spectator := range spectators {
+			node.Spectators = append(node.Spectators, spectator.Info.Id)
+		}
+
+		graph = append(graph, node)
+	}
+
+	writeJSON(w, http.StatusOK, graph)
+}
+
+// Sends an announcement to every online user
+func handleAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var body messageRequest
+
+	if err := readJSON(r, &body); err != nil || body.Message == "" {
+		writeError(w, http.StatusBadRequest, "a message is required")
+		return
+	}
+
+	chat.NotifyAllUsers(body.Message)
+	log.Printf("[Admin API] Sent announcement: %v\n", body.Message)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Your message has been notified to all online users."})
+}
+
+// Returns if the server is in maintenance mode
+func handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, maintenanceStatus{Enabled: sessions.IsMaintenanceEnabled()})
+}
+
+// Enables or disables maintenance mode. Lasts until it's changed again or the config is reloaded.
+func handleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var body maintenanceStatus
+
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sessions.SetMaintenanceMode(body.Enabled)
+	log.Printf("[Admin API] Set maintenance mode: %v\n", body.Enabled)
+	writeJSON(w, http.StatusOK, maintenanceStatus{Enabled: sessions.IsMaintenanceEnabled()})
+}
+
+// Returns the amount of users that were online and in each status over the past day
+func handleGetActivity(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, sessions.GetActivityHistory())
+}
+
+// Returns the seconds a user has spent in each status during their current session
+func getSessionPlaytime(user *sessions.User) map[string]int64 {
+	playtime := map[string]int64{}
+
+	for status, duration := range user.GetSessionStatusTimes() {
+		playtime[status.String()] = int64(duration.Seconds())
+	}
+
+	return playtime
+}
+
+// Returns the online user from the id in the request path. Writes an error response if they can't be found.
+func getOnlineUserFromPath(w http.ResponseWriter, r *http.Request) *sessions.User {
+	id, err := strconv.Atoi(r.PathValue("id"))
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id")
+		return nil
+	}
+
+	user := sessions.GetUserById(id)
+
+	if user == nil {
+		writeError(w, http.StatusNotFound, "user is not online")
+		return nil
+	}
+
+	return user
+}
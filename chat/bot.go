@@ -37,6 +37,7 @@ const (
 func addBotChatHandlers() {
 	AddPublicMessageHandler(handlePublicChatBotCommands)
 	AddPrivateMessageHandler(handlePrivateChatBotCommands)
+	AddPrivateMessageHandler(handleAwayAutoReply)
 }
 
 // Handles bot commands for public messages
@@ -44,6 +45,15 @@ func handlePublicChatBotCommands(user *sessions.User, channel *Channel, args []s
 	return handleBotCommands(user, args)
 }
 
+// Tells users who message someone that is away that they may not see the message
+func handleAwayAutoReply(user *sessions.User, receivingUser *sessions.User, args []string) string {
+	if !receivingUser.ShouldSendAwayReply(user.Info.Id) {
+		return ""
+	}
+
+	return fmt.Sprintf("%v is currently away and may not see your message.", receivingUser.Info.Username)
+}
+
 // Handles bot commands for private messages
 func handlePrivateChatBotCommands(user *sessions.User, receivingUser *sessions.User, args []string) string {
 	// Only handle if the user is talking to the bot directly.
@@ -51,16 +61,31 @@ func handlePrivateChatBotCommands(user *sessions.User, receivingUser *sessions.U
 		return ""
 	}
 
+	// Replies to these commands are only meant for the user, so they aren't answered in public channels
+	switch getBotCommand(args) {
+	case "recent":
+		return handleBotCommandRecentPlayers(user, args)
+	case "ban":
+		return handleBotCommandBan(user, args)
+	case "tempban":
+		return handleBotCommandTempBan(user, args)
+	}
+
 	return handleBotCommands(user, args)
 }
 
-// Handles all bot commands regardless of if they're public or private
-func handleBotCommands(user *sessions.User, args []string) string {
+// Returns the lowercase name of the command in a message, or an empty string if it isn't a command
+func getBotCommand(args []string) string {
 	if len(args) == 0 || args[0] == "" || args[0][0] != '!' {
 		return ""
 	}
 
-	switch strings.ToLower(strings.Split(args[0], "!")[1]) {
+	return strings.ToLower(strings.Split(args[0], "!")[1])
+}
+
+// Handles all bot commands regardless of if they're public or private
+func handleBotCommands(user *sessions.User, args []string) string {
+	switch getBotCommand(args) {
 	case "kick":
 		return handleBotCommandKick(user, args)
 	case "alertall", "notifyall":
@@ -71,6 +96,12 @@ func handleBotCommands(user *sessions.User, args []string) string {
 		return handleBotCommandMuteUser(user, args)
 	case "unmute":
 		return handleBotCommandUnmuteUser(user, args)
+	case "unban":
+		return handleBotCommandUnban(user, args)
+	case "maintenance":
+		return handleBotCommandMaintenance(user, args)
+	case "linked":
+		return handleBotCommandLinkedAccounts(user, args)
 	default:
 		return ""
 	}
@@ -96,8 +127,7 @@ func handleBotCommandKick(user *sessions.User, args []string) string {
 		return botMessageStop
 	}
 
-	sessions.SendPacketToUser(packets.NewServerNotificationError("You have been kicked from the server."), target)
-	utils.CloseConnectionDelayed(target.Conn)
+	KickUser(target)
 	return fmt.Sprintf("%v has been kicked from the server.", target.Info.Username)
 }
 
@@ -111,13 +141,7 @@ func handleBotCommandNotifyAll(user *sessions.User, args []string) string {
 		return "You must provide a message to notify everyone with."
 	}
 
-	notification := strings.Join(args[1:], " ")
-
-	for _, onlineUser := range sessions.GetOnlineUsers() {
-		sessions.SendPacketToUser(packets.NewServerNotificationInfo(notification), onlineUser)
-		sendPrivateMessage(Bot, onlineUser, notification)
-	}
-
+	NotifyAllUsers(strings.Join(args[1:], " "))
 	return "Your message has been notified to all online users."
 }
 
@@ -145,11 +169,7 @@ func handleBotCommandNotifyUser(user *sessions.User, args []string) string {
 		return "You must provide a message to notify this user with."
 	}
 
-	notification := strings.Join(args[2:], " ")
-
-	sessions.SendPacketToUser(packets.NewServerNotificationInfo(notification), target)
-	sendPrivateMessage(Bot, target, notification)
-
+	NotifyUser(target, strings.Join(args[2:], " "))
 	return fmt.Sprintf("Your notification has been sent to: %v.", target.Info.Username)
 }
 
@@ -192,33 +212,13 @@ func handleBotCommandMuteUser(user *sessions.User, args []string) string {
 		return "You must provide a duration value (ex. s/m/h/d)."
 	}
 
-	var duration time.Duration
+	duration, err := parseCommandDuration(timeVal, args[3])
 
-	switch strings.ToLower(args[3]) {
-	case "s":
-		duration = time.Second * time.Duration(timeVal)
-	case "m":
-		duration = time.Minute * time.Duration(timeVal)
-	case "h":
-		duration = time.Hour * time.Duration(timeVal)
-	case "d":
-		duration = time.Hour * 24 * time.Duration(timeVal)
-	default:
+	if err != nil {
 		return "You have specified an invalid duration of time."
 	}
 
-	// Try to find the user online. If not, create a "temp" user since that has mute capabilities.
-	// Doing it this way because we need to update the mute time of the online user right away,
-	// but if they're offline, we can just skip to updating it in the DB.
-	onlineUser := getUserFromCommandArgs(args)
-
-	if onlineUser != nil {
-		err = onlineUser.MuteUser(duration)
-	} else {
-		err = sessions.NewUser(nil, target).MuteUser(duration)
-	}
-
-	if err != nil {
+	if err := MuteUser(target, duration); err != nil {
 		log.Printf("Error while muting user - %v - %v\n", target.Id, err)
 		return "An error occurred while muting this user."
 	}
@@ -240,7 +240,329 @@ func handleBotCommandUnmuteUser(user *sessions.User, args []string) string {
 	return handleBotCommandMuteUser(user, []string{"unmute", args[1], "0", "s"})
 }
 
+// Handles the command to permanently ban a user
+func handleBotCommandBan(user *sessions.User, args []string) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeBanUsers) {
+		return ""
+	}
+
+	if len(args) < 2 {
+		return "You must specify a user to ban."
+	}
+
+	target, message := getBanTargetFromCommandArgs(args)
+
+	if target == nil {
+		return message
+	}
+
+	if !canModerateUser(user.Info, target) {
+		return "You cannot ban users that have the same or a higher rank than you."
+	}
+
+	if err := BanUser(target, 0, strings.Join(args[2:], " ")); err != nil {
+		log.Printf("Error while banning user - %v - %v\n", target.Id, err)
+		return "An error occurred while banning this user."
+	}
+
+	return fmt.Sprintf("%v has been permanently banned.", target.Username)
+}
+
+// Handles the command to ban a user for a given amount of time
+func handleBotCommandTempBan(user *sessions.User, args []string) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeBanUsers) {
+		return ""
+	}
+
+	if len(args) < 2 {
+		return "You must specify a user to ban."
+	}
+
+	target, message := getBanTargetFromCommandArgs(args)
+
+	if target == nil {
+		return message
+	}
+
+	if !canModerateUser(user.Info, target) {
+		return "You cannot ban users that have the same or a higher rank than you."
+	}
+
+	if len(args) < 4 {
+		return "You must provide a time and duration value (ex. 7 d)."
+	}
+
+	timeVal, err := strconv.Atoi(args[2])
+
+	if err != nil || timeVal <= 0 {
+		return "You must provide a valid number as a time value."
+	}
+
+	duration, err := parseCommandDuration(timeVal, args[3])
+
+	if err != nil {
+		return "You have specified an invalid duration of time."
+	}
+
+	if err := BanUser(target, duration, strings.Join(args[4:], " ")); err != nil {
+		log.Printf("Error while banning user - %v - %v\n", target.Id, err)
+		return "An error occurred while banning this user."
+	}
+
+	return fmt.Sprintf("%v has been banned for %v.", target.Username, duration.String())
+}
+
+// Handles the command to lift a user's ban
+func handleBotCommandUnban(user *sessions.User, args []string) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeBanUsers) {
+		return ""
+	}
+
+	if len(args) < 2 {
+		return "You must specify a user to unban."
+	}
+
+	target, message := getBanTargetFromCommandArgs(args)
+
+	if target == nil {
+		return message
+	}
+
+	if target.Allowed {
+		return fmt.Sprintf("%v is not banned.", target.Username)
+	}
+
+	if err := db.UnbanUser(target.Id); err != nil {
+		log.Printf("Error while unbanning user - %v - %v\n", target.Id, err)
+		return "An error occurred while unbanning this user."
+	}
+
+	return fmt.Sprintf("%v has been unbanned.", target.Username)
+}
+
+// Returns the user to ban from command args, or the message to respond with if they can't be banned
+func getBanTargetFromCommandArgs(args []string) (*db.User, string) {
+	target, err := db.GetUserByUsername(strings.ToLower(strings.ReplaceAll(args[1], "_", " ")))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "That user does not exist."
+		}
+
+		log.Printf("Error retrieving user from the database - %v\n", err)
+		return nil, "An error occurred while executing this command."
+	}
+
+	if common.HasUserGroup(target.UserGroups, common.UserGroupBot) {
+		return nil, "You cannot ban bot users."
+	}
+
+	return target, ""
+}
+
+// Returns if a user outranks a target. Targets that have every privilege the user has, or are in every group the user
+// is in, have the same or a higher rank.
+func canModerateUser(user *db.User, target *db.User) bool {
+	if target.Privileges&user.Privileges == user.Privileges {
+		return false
+	}
+
+	return target.UserGroups&user.UserGroups != user.UserGroups
+}
+
+// Parses a time value and a unit of time (s/m/h/d) into a duration
+func parseCommandDuration(timeVal int, unit string) (time.Duration, error) {
+	switch strings.ToLower(unit) {
+	case "s":
+		return time.Second * time.Duration(timeVal), nil
+	case "m":
+		return time.Minute * time.Duration(timeVal), nil
+	case "h":
+		return time.Hour * time.Duration(timeVal), nil
+	case "d":
+		return time.Hour * 24 * time.Duration(timeVal), nil
+	default:
+		return 0, fmt.Errorf("invalid unit of time: %v", unit)
+	}
+}
+
+// Handles the command to turn maintenance mode on or off
+func handleBotCommandMaintenance(user *sessions.User, args []string) string {
+	if !common.HasAnyUserGroup(user.Info.UserGroups, []common.UserGroups{common.UserGroupAdmin, common.UserGroupDeveloper}) {
+		return ""
+	}
+
+	if len(args) < 2 {
+		if sessions.IsMaintenanceEnabled() {
+			return "Maintenance mode is currently enabled."
+		}
+
+		return "Maintenance mode is currently disabled."
+	}
+
+	switch strings.ToLower(args[1]) {
+	case "on":
+		sessions.SetMaintenanceMode(true)
+		return "Maintenance mode has been enabled. Users who aren't allowed on the server have been disconnected."
+	case "off":
+		sessions.SetMaintenanceMode(false)
+		return "Maintenance mode has been disabled."
+	default:
+		return "You must specify either on or off."
+	}
+}
+
+// Handles the command to list the accounts that share hardware ids or ip addresses with a user
+func handleBotCommandLinkedAccounts(user *sessions.User, args []string) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeBanUsers) {
+		return ""
+	}
+
+	if len(args) < 2 {
+		return "You must specify a user to look up linked accounts for."
+	}
+
+	target, err := db.GetUserByUsername(strings.ToLower(strings.ReplaceAll(args[1], "_", " ")))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "That user does not exist."
+		}
+
+		log.Printf("Error retrieving user from the database - %v\n", err)
+		return "An error occurred while executing this command."
+	}
+
+	accounts, err := db.GetLinkedAccounts(target.Id)
+
+	if err != nil {
+		log.Printf("Error retrieving linked accounts - %v - %v\n", target.Id, err)
+		return "An error occurred while executing this command."
+	}
+
+	if len(accounts) == 0 {
+		return fmt.Sprintf("%v has no linked accounts.", target.Username)
+	}
+
+	formatted := make([]string, 0, len(accounts))
+
+	for _, account := range accounts {
+		banned := ""
+
+		if !account.Allowed {
+			banned = ", banned"
+		}
+
+		formatted = append(formatted, fmt.Sprintf("%v (#%v, score: %v%v)", account.Username, account.UserId, account.Score(), banned))
+	}
+
+	return fmt.Sprintf("Linked accounts for %v: %v", target.Username, strings.Join(formatted, ", "))
+}
+
+// KickUser Notifies a user that they have been kicked and disconnects them from the server
+func KickUser(user *sessions.User) {
+	sessions.SendPacketToUser(packets.NewServerNotificationError("You have been kicked from the server."), user)
+	utils.CloseConnectionDelayed(user.Conn)
+}
+
+// NotifyAllUsers Sends a notification and a message from the bot to every online user
+func NotifyAllUsers(notification string) {
+	for _, onlineUser := range sessions.GetOnlineUsers() {
+		NotifyUser(onlineUser, notification)
+	}
+}
+
+// NotifyUser Sends a notification and a message from the bot to a user
+func NotifyUser(user *sessions.User, notification string) {
+	sessions.SendPacketToUser(packets.NewServerNotificationInfo(notification), user)
+	sendPrivateMessage(Bot, user, notification)
+}
+
+// MuteUser Mutes a user for a given duration. A duration of zero unmutes the user.
+func MuteUser(user *db.User, duration time.Duration) error {
+	// Try to find the user online. If not, create a "temp" user since that has mute capabilities.
+	// Doing it this way because we need to update the mute time of the online user right away,
+	// but if they're offline, we can just skip to updating it in the DB.
+	if onlineUser := sessions.GetUserById(user.Id); onlineUser != nil {
+		return onlineUser.MuteUser(duration)
+	}
+
+	return sessions.NewUser(nil, user).MuteUser(duration)
+}
+
+// BanUser Bans a user for a given duration and disconnects them if they're online. A duration of zero bans the user permanently.
+func BanUser(user *db.User, duration time.Duration, reason string) error {
+	var endTime int64
+
+	if duration > 0 {
+		endTime = time.Now().Add(duration).UnixMilli()
+	}
+
+	if err := db.BanUser(user.Id, endTime, reason); err != nil {
+		return err
+	}
+
+	user.Allowed = false
+	user.BanEndTime = endTime
+	user.BanReason = sql.NullString{String: reason, Valid: reason != ""}
+
+	if onlineUser := sessions.GetUserById(user.Id); onlineUser != nil {
+		sessions.SendPacketToUser(packets.NewServerNotificationError(user.GetBanMessage()), onlineUser)
+		utils.CloseConnectionDelayed(onlineUser.Conn)
+	}
+
+	return nil
+}
+
 // getUserFromCommandArgs Returns a target user from command args
 func getUserFromCommandArgs(args []string) *sessions.User {
 	return sessions.GetUserByUsername(strings.ToLower(strings.ReplaceAll(args[1], "_", " ")))
 }
+
+// Handles the command to list the users someone has recently played with
+func handleBotCommandRecentPlayers(user *sessions.User, args []string) string {
+	players, err := db.GetRecentPlayers(user.Info.Id, 10)
+
+	if err != nil {
+		log.Printf("Error retrieving recent players - %v - %v\n", user.Info.Id, err)
+		return "An error occurred while executing this command."
+	}
+
+	if len(players) == 0 {
+		return "You haven't played with anyone recently."
+	}
+
+	formatted := make([]string, 0, len(players))
+
+	for _, player := range players {
+		where := "spectating"
+
+		if player.Type == db.RecentPlayerMultiplayer {
+			where = "multiplayer"
+
+			if player.GameName.Valid {
+				where = player.GameName.String
+			}
+		}
+
+		formatted = append(formatted, fmt.Sprintf("%v (%v, %v)", player.Username, where, formatTimeAgo(player.Timestamp)))
+	}
+
+	return fmt.Sprintf("Recently played with: %v", strings.Join(formatted, ", "))
+}
+
+// Formats how long ago a unix millisecond timestamp was (ex. 5m ago)
+func formatTimeAgo(timestamp int64) string {
+	elapsed := time.Since(time.UnixMilli(timestamp))
+
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%vm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%vh ago", int(elapsed.Hours()))
+	default:
+		return fmt.Sprintf("%vd ago", int(elapsed.Hours()/24))
+	}
+}
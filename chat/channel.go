@@ -18,6 +18,7 @@ type Channel struct {
 	AutoJoin       bool
 	LimitedChat    bool
 	DiscordWebhook string
+	HistoryLength  int
 	WebhookClient  webhook.Client
 	Participants   map[int]*sessions.User
 	mutex          *sync.Mutex
@@ -33,7 +34,8 @@ const (
 )
 
 // NewChannel Creates a new chat channel instance
-func NewChannel(channelType ChannelType, name string, description string, adminOnly bool, autoJoin bool, limitedChat bool, discordWebhook string) *Channel {
+func NewChannel(channelType ChannelType, name string, description string, adminOnly bool, autoJoin bool, limitedChat bool, discordWebhook string,
+	historyLength int) *Channel {
 	channel := Channel{
 		Type:           channelType,
 		Name:           name,
@@ -42,6 +44,7 @@ func NewChannel(channelType ChannelType, name string, description string, adminO
 		AutoJoin:       autoJoin,
 		LimitedChat:    limitedChat,
 		DiscordWebhook: discordWebhook,
+		HistoryLength:  historyLength,
 		WebhookClient:  nil,
 		Participants:   map[int]*sessions.User{},
 		mutex:          &sync.Mutex{},
@@ -85,6 +88,10 @@ func (channel *Channel) AddUser(user *sessions.User) {
 
 	channel.Participants[user.Info.Id] = user
 	sessions.SendPacketToUser(packets.NewServerJoinedChatChannel(channel.Name), user)
+
+	if channel.HistoryLength > 0 {
+		go channel.sendHistory(user, 0)
+	}
 }
 
 // RemoveUser Removes a user from the channel
@@ -109,15 +116,19 @@ func (channel *Channel) SendMessage(sender *sessions.User, message string) {
 	defer channel.mutex.Unlock()
 
 	packet := packets.NewServerChatMessage(sender.Info.Id, sender.Info.Username, sender.Info.ClanTag.String, sender.Info.ClanAccentColor.String, channel.Name, message)
+	receivers := make([]*sessions.User, 0, len(channel.Participants))
 
 	for _, user := range channel.Participants {
-		if user == sender {
+		// Messages (and mentions) aren't delivered between users when either of them has blocked the other
+		if user == sender || sessions.IsBlockedBetween(sender, user) {
 			continue
 		}
 
-		sessions.SendPacketToUser(packet, user)
+		receivers = append(receivers, user)
 	}
 
+	sessions.SendPacketToUsers(packet, receivers...)
+
 	err := db.InsertPublicChatMessage(sender.Info.Id, channel.Name, message)
 
 	if err != nil {
@@ -125,6 +136,29 @@ func (channel *Channel) SendMessage(sender *sessions.User, message string) {
 	}
 }
 
+// GetParticipantCount Returns the amount of users in the channel
+func (channel *Channel) GetParticipantCount() int {
+	channel.mutex.Lock()
+	defer channel.mutex.Unlock()
+
+	return len(channel.Participants)
+}
+
+// Returns the label the channel's messages are counted under. Channels that are created on the fly are grouped
+// by their type, so they don't create a new label each.
+func (channel *Channel) getMetricsLabel() string {
+	switch channel.Type {
+	case ChannelTypeMultiplayer:
+		return "#multiplayer"
+	case ChannelTypeSpectator:
+		return "#spectator"
+	case ChannelTypeClan:
+		return "#clan"
+	default:
+		return channel.Name
+	}
+}
+
 // Sends a discord webhook
 func (channel *Channel) sendWebhook(sender *sessions.User, message string) {
 	webhooks.SendChatMessage(channel.WebhookClient, sender.Info.Username, sender.Info.GetProfileUrl(), sender.Info.AvatarUrl.String, channel.Name, message)
@@ -6,11 +6,11 @@ import (
 	"regexp"
 	"strings"
 	"sync"
-	"time"
 
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/metrics"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
@@ -31,8 +31,8 @@ func Initialize() {
 	publicMessageHandlers = []func(user *sessions.User, channel *Channel, args []string) string{}
 	privateMessageHandlers = []func(user *sessions.User, receiver *sessions.User, args []string) string{}
 
-	for _, channel := range config.Instance.ChatChannels {
-		addChannel(NewChannel(ChannelNormal, channel.Name, channel.Description, channel.AdminOnly, channel.AutoJoin, channel.LimitedChat, channel.DiscordWebhook))
+	for _, channel := range config.Get().ChatChannels {
+		addChannel(NewChannel(ChannelNormal, channel.Name, channel.Description, channel.AdminOnly, channel.AutoJoin, channel.LimitedChat, channel.DiscordWebhook, channel.HistoryLength))
 	}
 
 	clans, err := db.GetAllClans()
@@ -69,6 +69,20 @@ func GetAvailableChannels(userGroups common.UserGroups) []*Channel {
 	return availableChannels
 }
 
+// GetAllChannels Returns every chat channel, including multiplayer, spectator and clan channels
+func GetAllChannels() []*Channel {
+	chatMutex.Lock()
+	defer chatMutex.Unlock()
+
+	allChannels := make([]*Channel, 0, len(channels))
+
+	for _, channel := range channels {
+		allChannels = append(allChannels, channel)
+	}
+
+	return allChannels
+}
+
 // GetChannelByName Gets a chat channel by its name
 func GetChannelByName(name string) *Channel {
 	chatMutex.Lock()
@@ -93,12 +107,12 @@ func SendMessage(sender *sessions.User, receiver string, message string) {
 
 	sender.IncrementSpammedMessagesCount()
 
-	if sender.GetSpammedMessagesCount() >= 10 && !isChatModerator(sender.Info.UserGroups) {
-		_ = sender.MuteUser(time.Minute * 30)
+	if sender.GetSpammedMessagesCount() >= config.Get().Chat.SpamMessageLimit && !isChatModerator(sender.Info.UserGroups) {
+		_ = sender.MuteUser(config.Get().Chat.SpamMuteDuration.Duration())
 		return
 	}
 
-	message = utils.TruncateString(message, 500)
+	message = utils.TruncateString(message, config.Get().Chat.MaxMessageLength)
 	uncensoredMessage := message
 
 	if censored := utils.CensorString(message); censored != "" {
@@ -121,6 +135,7 @@ func SendMessage(sender *sessions.User, receiver string, message string) {
 		}
 
 		channel.SendMessage(sender, message)
+		metrics.ChatMessages.WithLabelValues(channel.getMetricsLabel()).Inc()
 		webhooks.SendChatMessage(channel.WebhookClient, sender.Info.Username, sender.Info.GetProfileUrl(), sender.Info.AvatarUrl.String, receiver, message)
 		runPublicMessageHandlers(sender, channel, message)
 	} else {
@@ -130,7 +145,11 @@ func SendMessage(sender *sessions.User, receiver string, message string) {
 			return
 		}
 
-		sendPrivateMessage(sender, receivingUser, message)
+		if !sendPrivateMessage(sender, receivingUser, message) {
+			return
+		}
+
+		metrics.ChatMessages.WithLabelValues("private").Inc()
 		webhooks.SendChatMessage(webhooks.PrivateChat, sender.Info.Username, sender.Info.GetProfileUrl(), sender.Info.AvatarUrl.String, receiver, message)
 		runPrivateMessageHandlers(sender, receivingUser, message)
 	}
@@ -139,7 +158,7 @@ func SendMessage(sender *sessions.User, receiver string, message string) {
 // AddMultiplayerChannel Adds a multiplayer channel.
 func AddMultiplayerChannel(id string) *Channel {
 	channel := NewChannel(ChannelTypeMultiplayer, fmt.Sprintf("#multiplayer_%v", id), "", false, false,
-		false, config.Instance.DiscordWebhooks.Multiplayer)
+		false, config.Get().DiscordWebhooks.Multiplayer, 0)
 
 	addChannel(channel)
 	return channel
@@ -164,7 +183,7 @@ func GetSpectatorChannel(userId int) *Channel {
 // AddSpectatorChannel Adds a spectator channel for a user
 func AddSpectatorChannel(userId int) *Channel {
 	channel := NewChannel(ChannelTypeSpectator, getSpectatorChannelName(userId), "", false, false,
-		false, config.Instance.DiscordWebhooks.Spectator)
+		false, config.Get().DiscordWebhooks.Spectator, 0)
 
 	addChannel(channel)
 
@@ -198,16 +217,21 @@ func AddPrivateMessageHandler(f func(user *sessions.User, receivingUser *session
 	privateMessageHandlers = append(privateMessageHandlers, f)
 }
 
-// Sends a private message to a user
-func sendPrivateMessage(sender *sessions.User, receiver *sessions.User, message string) {
+// Sends a private message to a user. Returns false if the message wasn't sent because one of the users blocked the other.
+func sendPrivateMessage(sender *sessions.User, receiver *sessions.User, message string) bool {
+	if sessions.IsBlockedBetween(sender, receiver) {
+		return false
+	}
+
 	sessions.SendPacketToUser(packets.NewServerChatMessage(sender.Info.Id, sender.Info.Username, sender.Info.ClanTag.String, sender.Info.ClanAccentColor.String, receiver.Info.Username, message), receiver)
 
 	err := db.InsertPrivateChatMessage(sender.Info.Id, receiver.Info.Id, receiver.Info.Username, message)
 
 	if err != nil {
 		log.Printf("Error inserting private chat into DB: %v\n", err)
-		return
 	}
+
+	return true
 }
 
 // Runs all the chat message handlers for a given public channel message.
@@ -262,7 +286,7 @@ func AddClanChannel(clan *db.Clan) *Channel {
 	name := fmt.Sprintf("#clan_%v", clan.Id)
 
 	channel := NewChannel(ChannelTypeClan, name, fmt.Sprintf("[%v] %v - Private Clan Chat", clan.Tag, clan.Name),
-		false, false, false, "")
+		false, false, false, "", 0)
 
 	addChannel(channel)
 
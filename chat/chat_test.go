@@ -3,19 +3,22 @@ package chat
 import (
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/sessions"
 	"testing"
+	"time"
 )
 
 func TestInitialize(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
 	Initialize()
 
-	if len(channels) != len(config.Instance.ChatChannels) {
+	if len(channels) != len(config.Get().ChatChannels) {
 		t.Fatal("Expected more than zero initialized chat channels")
 	}
 }
@@ -23,7 +26,7 @@ func TestInitialize(t *testing.T) {
 func TestGetAvailableChannels(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -41,3 +44,90 @@ func TestGetAvailableChannels(t *testing.T) {
 		t.Fatal("expected only one channel without admin")
 	}
 }
+
+func TestReloadChannels(t *testing.T) {
+	_ = config.Load("../config.json")
+
+	if config.Get() == nil || len(config.Get().ChatChannels) == 0 {
+		return
+	}
+
+	Initialize()
+
+	original := config.Get().ChatChannels
+	defer func() { config.Get().ChatChannels = original }()
+
+	removed := original[0].Name
+	added := original[0]
+	added.Name = "#reloaded"
+
+	config.Get().ChatChannels = append(append(original[:0:0], original[1:]...), added)
+
+	ReloadChannels()
+
+	if GetChannelByName(removed) != nil {
+		t.Fatalf("expected %v to be removed", removed)
+	}
+
+	if GetChannelByName("#reloaded") == nil {
+		t.Fatal("expected #reloaded to be added")
+	}
+}
+
+func TestParseCommandDuration(t *testing.T) {
+	duration, err := parseCommandDuration(7, "D")
+
+	if err != nil || duration != 7*24*time.Hour {
+		t.Fatalf("expected 7 days, got %v (%v)", duration, err)
+	}
+
+	if _, err := parseCommandDuration(7, "w"); err == nil {
+		t.Fatal("expected an error for an invalid unit of time")
+	}
+}
+
+func TestFormatTimeAgo(t *testing.T) {
+	if formatted := formatTimeAgo(time.Now().Add(-90 * time.Minute).UnixMilli()); formatted != "1h ago" {
+		t.Fatalf("expected 1h ago, got %v", formatted)
+	}
+
+	if formatted := formatTimeAgo(time.Now().UnixMilli()); formatted != "just now" {
+		t.Fatalf("expected just now, got %v", formatted)
+	}
+}
+
+func TestRecentPlayersCommandIsPrivate(t *testing.T) {
+	user := sessions.NewUser(nil, &db.User{Id: 1, Username: "User #1"})
+
+	if reply := handlePublicChatBotCommands(user, nil, []string{"!recent"}); reply != "" {
+		t.Fatalf("expected no reply in a public channel, got %q", reply)
+	}
+}
+
+func TestBanCommandsArePrivate(t *testing.T) {
+	user := sessions.NewUser(nil, &db.User{Id: 1, Username: "User #1", Privileges: common.PrivilegeBanUsers})
+
+	for _, command := range []string{"!ban", "!tempban"} {
+		if reply := handlePublicChatBotCommands(user, nil, []string{command}); reply != "" {
+			t.Fatalf("expected no reply to %v in a public channel, got %q", command, reply)
+		}
+	}
+}
+
+func TestCanModerateUser(t *testing.T) {
+	moderator := &db.User{Privileges: common.PrivilegeNormal | common.PrivilegeBanUsers, UserGroups: common.UserGroupNormal | common.UserGroupModerator}
+	admin := &db.User{Privileges: common.PrivilegeNormal | common.PrivilegeBanUsers | common.PrivilegeKickUsers, UserGroups: common.UserGroupNormal | common.UserGroupAdmin}
+	normal := &db.User{Privileges: common.PrivilegeNormal, UserGroups: common.UserGroupNormal}
+
+	if !canModerateUser(moderator, normal) {
+		t.Fatal("expected a moderator to be able to moderate a normal user")
+	}
+
+	if canModerateUser(moderator, moderator) {
+		t.Fatal("expected a moderator to not be able to moderate someone with the same rank")
+	}
+
+	if canModerateUser(moderator, admin) {
+		t.Fatal("expected a moderator to not be able to moderate someone with a higher rank")
+	}
+}
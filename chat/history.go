@@ -0,0 +1,70 @@
+package chat
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"log"
+)
+
+// SendChatHistory Sends a user the messages that were sent in a channel before a given message id.
+// Users can only load the history of channels they're in, so admin only channels stay hidden from everyone else.
+func SendChatHistory(user *sessions.User, channelName string, before int) {
+	channel := GetChannelByName(channelName)
+
+	if channel == nil || !channel.isUserInChannel(user) {
+		return
+	}
+
+	channel.sendHistory(user, before)
+}
+
+// Sends a user a page of the messages that were sent in the channel, leaving out the ones from users they've blocked
+func (channel *Channel) sendHistory(user *sessions.User, before int) {
+	channel.mutex.Lock()
+	limit := channel.HistoryLength
+	canSee := channel.canUserSee(user)
+	channel.mutex.Unlock()
+
+	if limit <= 0 || !canSee {
+		return
+	}
+
+	history, err := db.GetPublicChatMessages(channel.Name, before, limit)
+
+	if err != nil {
+		log.Printf("Failed to retrieve chat history of %v - %v\n", channel.Name, err)
+		return
+	}
+
+	messages := make([]*objects.ChatMessage, 0, len(history))
+
+	// Messages are retrieved newest first, but are sent oldest first
+	for i := len(history) - 1; i >= 0; i-- {
+		message := history[i]
+
+		if user.HasBlocked(message.SenderId) {
+			continue
+		}
+
+		messages = append(messages, &objects.ChatMessage{
+			Id:                    message.Id,
+			SenderId:              message.SenderId,
+			SenderName:            message.SenderName,
+			SenderClanTag:         message.SenderClanTag.String,
+			SenderClanAccentColor: message.SenderClanAccentColor.String,
+			Message:               message.Message,
+			Time:                  message.Timestamp,
+		})
+	}
+
+	// Blocked messages are left out, so the next page starts after the oldest retrieved message, not the oldest sent one
+	next := 0
+
+	if len(history) == limit {
+		next = history[len(history)-1].Id
+	}
+
+	sessions.SendPacketToUser(packets.NewServerChatHistory(channel.Name, messages, next), user)
+}
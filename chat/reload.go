@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"context"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"log"
+)
+
+// ReloadChannels Adds, updates and removes the public chat channels to match the config. Users stay connected
+// and are notified of the channels they can no longer see and the ones that have become available to them.
+func ReloadChannels() {
+	existing := map[string]*Channel{}
+
+	for _, channel := range GetAllChannels() {
+		if channel.Type == ChannelNormal {
+			existing[channel.Name] = channel
+		}
+	}
+
+	for _, channelConfig := range config.Get().ChatChannels {
+		if channel, ok := existing[channelConfig.Name]; ok {
+			channel.update(channelConfig.Description, channelConfig.AdminOnly, channelConfig.AutoJoin,
+				channelConfig.LimitedChat, channelConfig.DiscordWebhook, channelConfig.HistoryLength)
+
+			delete(existing, channelConfig.Name)
+			continue
+		}
+
+		updated := NewChannel(ChannelNormal, channelConfig.Name, channelConfig.Description, channelConfig.AdminOnly,
+			channelConfig.AutoJoin, channelConfig.LimitedChat, channelConfig.DiscordWebhook, channelConfig.HistoryLength)
+
+		addChannel(updated)
+
+		for _, user := range sessions.GetOnlineUsers() {
+			if !updated.canUserSee(user) {
+				continue
+			}
+
+			sessions.SendPacketToUser(packets.NewServerAvailableChatChannel(updated.Name, updated.Description), user)
+
+			if updated.AutoJoin {
+				updated.AddUser(user)
+			}
+		}
+	}
+
+	// Channels that are left over have been removed from the config
+	for _, channel := range existing {
+		for _, user := range sessions.GetOnlineUsers() {
+			if channel.canUserSee(user) && !channel.isUserInChannel(user) {
+				sessions.SendPacketToUser(packets.NewServerLeftChatChannel(channel.Name), user)
+			}
+		}
+
+		removeChannel(channel)
+	}
+
+	log.Println("Chat channels have been reloaded")
+}
+
+// Applies new settings to the channel, notifying users that gained or lost access to it
+func (channel *Channel) update(description string, adminOnly bool, autoJoin bool, limitedChat bool, discordWebhook string, historyLength int) {
+	channel.mutex.Lock()
+
+	wasAdminOnly := channel.AdminOnly
+	descriptionChanged := channel.Description != description
+
+	channel.Description = description
+	channel.AdminOnly = adminOnly
+	channel.AutoJoin = autoJoin
+	channel.LimitedChat = limitedChat
+	channel.HistoryLength = historyLength
+
+	if channel.DiscordWebhook != discordWebhook {
+		if channel.WebhookClient != nil {
+			channel.WebhookClient.Close(context.Background())
+		}
+
+		channel.DiscordWebhook = discordWebhook
+		channel.WebhookClient = nil
+		channel.initializeWebhook()
+	}
+
+	channel.mutex.Unlock()
+
+	// The new settings are used from here on rather than the channel's fields, which are only safe to read under the lock
+	for _, user := range sessions.GetOnlineUsers() {
+		moderator := isChatModerator(user.Info.UserGroups)
+
+		switch {
+		case adminOnly && !wasAdminOnly && !moderator:
+			// RemoveUser lets participants know that they've left, so only notify the others
+			if channel.isUserInChannel(user) {
+				channel.RemoveUser(user)
+			} else {
+				sessions.SendPacketToUser(packets.NewServerLeftChatChannel(channel.Name), user)
+			}
+		case !adminOnly && wasAdminOnly && !moderator, descriptionChanged && (!adminOnly || moderator):
+			sessions.SendPacketToUser(packets.NewServerAvailableChatChannel(channel.Name, description), user)
+		}
+	}
+}
+
+// Returns if a user is able to see and join the channel
+func (channel *Channel) canUserSee(user *sessions.User) bool {
+	return !channel.AdminOnly || isChatModerator(user.Info.UserGroups)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"example.com/Quaver/Z/config"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Upgrades an http request to a websocket connection. If compression is enabled, permessage-deflate is
+// offered to the client. Clients that don't request the extension fall back to uncompressed messages.
+func upgradeConnection(r *http.Request, w http.ResponseWriter) (net.Conn, bool, error) {
+	if settings := config.Get(); settings == nil || !settings.Compression.Enabled {
+		conn, _, _, err := ws.UpgradeHTTP(r, w)
+		return conn, false, err
+	}
+
+	extension := wsflate.Extension{
+		Parameters: wsflate.DefaultParameters,
+	}
+
+	upgrader := ws.HTTPUpgrader{
+		Negotiate: extension.Negotiate,
+	}
+
+	conn, _, _, err := upgrader.Upgrade(r, w)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, accepted := extension.Accepted()
+	return conn, accepted, nil
+}
+
+// Reads the next data message from a client. Control frames are handled along the way.
+func readClientData(conn net.Conn, compression bool) ([]byte, ws.OpCode, error) {
+	if !compression {
+		return wsutil.ReadClientData(conn)
+	}
+
+	state := &wsflate.MessageState{}
+	controlHandler := wsutil.ControlFrameHandler(conn, ws.StateServerSide)
+
+	// UTF-8 can't be checked until the message is decompressed
+	reader := wsutil.Reader{
+		Source:         conn,
+		State:          ws.StateServerSide | ws.StateExtended,
+		CheckUTF8:      false,
+		OnIntermediate: controlHandler,
+		Extensions:     []wsutil.RecvExtension{state},
+	}
+
+	for {
+		header, err := reader.NextFrame()
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if header.OpCode.IsControl() {
+			if err := controlHandler(header, &reader); err != nil {
+				return nil, 0, err
+			}
+
+			continue
+		}
+
+		msg, err := io.ReadAll(&reader)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if state.IsCompressed() {
+			msg, err = wsflate.DefaultHelper.Decompress(msg)
+
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+
+		return msg, header.OpCode, nil
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"example.com/Quaver/Z/db"
+	"log"
+	"net/http"
+	"time"
+)
+
+// How long a single readiness check can take before it is considered failed
+const healthCheckTimeout = 2 * time.Second
+
+type healthCheck struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type healthResponse struct {
+	Status   string                 `json:"status"`
+	Draining bool                   `json:"draining"`
+	Checks   map[string]healthCheck `json:"checks,omitempty"`
+}
+
+// Handles liveness probes. The server is alive as long as it can respond.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(w, http.StatusOK, healthResponse{
+		Status:   "ok",
+		Draining: s.IsDraining(),
+	})
+}
+
+// Handles readiness probes. The server is ready if all of its dependencies are healthy and it isn't draining.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	response := healthResponse{
+		Status:   "ready",
+		Draining: s.IsDraining(),
+		Checks: map[string]healthCheck{
+			"sql":              runHealthCheck(ctx, db.PingSQL),
+			"redis":            runHealthCheck(ctx, db.PingRedis),
+			"redis_subscriber": runHealthCheck(ctx, db.PingRedisSubscriber),
+		},
+	}
+
+	status := http.StatusOK
+
+	for _, check := range response.Checks {
+		if !check.Healthy {
+			response.Status = "not_ready"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	if response.Draining {
+		response.Status = "draining"
+		status = http.StatusServiceUnavailable
+	}
+
+	writeHealthResponse(w, status, response)
+}
+
+// Runs a single health check
+func runHealthCheck(ctx context.Context, check func(ctx context.Context) error) healthCheck {
+	if err := check(ctx); err != nil {
+		return healthCheck{Healthy: false, Error: err.Error()}
+	}
+
+	return healthCheck{Healthy: true}
+}
+
+// Writes a health check response as JSON
+func writeHealthResponse(w http.ResponseWriter, status int, response healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to write health check response - %v\n", err)
+	}
+}
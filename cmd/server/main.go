@@ -1,13 +1,20 @@
 package main
 
 import (
+	"example.com/Quaver/Z/admin"
 	"example.com/Quaver/Z/chat"
 	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/handlers"
 	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/webhooks"
 	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -18,7 +25,22 @@ func main() {
 		panic(err)
 	}
 
+	sessions.SetMaintenanceMode(config.Get().Maintenance.Enabled)
+
+	if len(config.Get().Server.TrustedProxies) == 0 {
+		log.Println("WARNING: server.trusted_proxies is empty, so X-Forwarded-For is ignored. " +
+			"If the server is behind a proxy, add its address or every user will appear to log in from it.")
+	}
+
 	db.InitializeSQL()
+
+	// Friends lists only contain mutual friends, so one-sided friends from before friend requests become requests
+	if converted, err := db.ConvertLegacyFriendsToRequests(); err != nil {
+		log.Printf("Failed to convert legacy friends into friend requests - %v\n", err)
+	} else if converted > 0 {
+		log.Printf("Converted %v legacy friends into friend requests\n", converted)
+	}
+
 	db.InitializeRedis()
 	handlers.AddRedisHandlers()
 	webhooks.Initialize()
@@ -26,6 +48,51 @@ func main() {
 	multiplayer.InitializeChatBot()
 	multiplayer.InitializeLobby()
 
-	s := NewServer(config.Instance.Server.Port)
+	go admin.Start()
+
+	s := NewServer(config.Get().Server.Port)
+	go drainOnShutdown(s)
+	go reloadOnHangup(*configPath)
 	s.Start()
 }
+
+// Drains the server when it is asked to shut down, giving load balancers time to stop sending new clients.
+// Users that are still online afterwards are logged out, so their sessions are cleaned up before exiting.
+func drainOnShutdown(s *Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+
+	<-signals
+
+	drainTimeout := config.Get().Server.DrainTimeout.Duration()
+
+	s.Drain()
+	log.Printf("Draining for %v before shutting down\n", drainTimeout)
+
+	time.Sleep(drainTimeout)
+	s.Shutdown()
+	os.Exit(0)
+}
+
+// Reloads the config file and chat channels whenever the server receives SIGHUP
+func reloadOnHangup(configPath string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		log.Println("Reloading config file")
+
+		previous := config.Get()
+
+		if err := config.Reload(configPath); err != nil {
+			log.Printf("Failed to reload config file - %v\n", err)
+			continue
+		}
+
+		// Only apply maintenance when it changed in the file, so a toggle made at runtime isn't overwritten
+		if enabled := config.Get().Maintenance.Enabled; enabled != previous.Maintenance.Enabled {
+			sessions.SetMaintenanceMode(enabled)
+		}
+		chat.ReloadChannels()
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/handlers"
 	"example.com/Quaver/Z/multiplayer"
 	"example.com/Quaver/Z/packets"
@@ -9,20 +11,26 @@ import (
 	"example.com/Quaver/Z/utils"
 	"fmt"
 	"github.com/gobwas/ws"
-	"github.com/gobwas/ws/wsutil"
 	"log"
 	"net"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// How often temporary bans that have run out are lifted
+const expiredBanLiftInterval = time.Minute
+
 type Server struct {
 	// The port the server is running on
 	Port int
 
 	// If the server is currently started
 	IsStarted bool
+
+	// If the server is shutting down and no longer accepts new logins
+	draining atomic.Bool
 }
 
 // NewServer Creates and returns a new server object.
@@ -46,66 +54,117 @@ func (s *Server) Start() {
 
 	clearPreviousSessions()
 	startBackgroundWorker()
+	startActivitySampler()
+	startExpiredBanLifter()
 
 	log.Printf("Starting server on port: %v\n", s.Port)
 
-	err := http.ListenAndServe(fmt.Sprintf(":%v", s.Port), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		conn, _, _, err := ws.UpgradeHTTP(r, w)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("/", s.handleWebSocket)
+
+	err := http.ListenAndServe(fmt.Sprintf(":%v", s.Port), mux)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Drain Stops accepting new logins and reports the server as not ready, so load balancers stop sending new clients
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+// Shutdown Logs out every user that is still online. This cleans up their sessions and saves their playtime.
+func (s *Server) Shutdown() {
+	users := sessions.GetOnlineUsers()
+	log.Printf("Logging out %v users before shutting down\n", len(users))
+
+	for _, user := range users {
+		// Bot users don't have a connection to close
+		if user.Conn == nil {
+			continue
+		}
+
+		if err := handlers.HandleLogout(user.Conn); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// IsDraining Returns if the server is draining
+func (s *Server) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// Upgrades a request to a WebSocket connection and handles the client's messages
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if s.IsDraining() {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, compression, err := upgradeConnection(r, w)
+
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	if strings.Contains(r.RequestURI, "/?login=") {
+		err := handlers.HandleLogin(conn, r, compression)
 
 		if err != nil {
 			log.Println(err)
+			utils.CloseConnection(conn)
 			return
 		}
+	} else {
+		_ = conn.Close()
+		return
+	}
+
+	// Handle various connection events
+	go func() {
+		defer conn.Close()
 
-		if strings.Contains(r.RequestURI, "/?login=") {
-			err := handlers.HandleLogin(conn, r)
+		for {
+			msg, op, err := readClientData(conn, compression)
 
 			if err != nil {
-				log.Println(err)
-				utils.CloseConnection(conn)
+				_ = s.onClose(conn)
 				return
 			}
-		} else {
-			_ = conn.Close()
-			return
-		}
 
-		// Handle various connection events
-		go func() {
-			defer conn.Close()
-
-			for {
-				msg, op, err := wsutil.ReadClientData(conn)
+			switch op {
+			case ws.OpText:
+				s.onTextMessage(conn, msg)
+				break
+			case ws.OpBinary:
+				s.onBinaryMessage(conn, msg)
+				break
+			case ws.OpClose:
+				err := s.onClose(conn)
 
 				if err != nil {
-					_ = s.onClose(conn)
-					return
-				}
-
-				switch op {
-				case ws.OpText:
-					s.onTextMessage(conn, msg)
-					break
-				case ws.OpClose:
-					err := s.onClose(conn)
-
-					if err != nil {
-						log.Println(err)
-					}
-					break
+					log.Println(err)
 				}
+				break
 			}
-		}()
-	}))
-
-	if err != nil {
-		panic(err)
-	}
+		}
+	}()
 }
 
 // Handles new incoming text messages
 func (s *Server) onTextMessage(conn net.Conn, msg []byte) {
-	handlers.HandleIncomingPackets(conn, string(msg))
+	handlers.HandleIncomingPackets(conn, msg, packets.EncodingJSON)
+}
+
+// Handles new incoming binary messages
+func (s *Server) onBinaryMessage(conn net.Conn, msg []byte) {
+	handlers.HandleIncomingPackets(conn, msg, packets.EncodingMessagePack)
 }
 
 // Handles when a connection has been closed
@@ -153,6 +212,8 @@ func startBackgroundWorker() {
 	go func() {
 		for {
 			users := sessions.GetOnlineUsers()
+			timings := config.Get().Server
+			spamWindow := config.Get().Chat.SpamWindow.Duration().Milliseconds()
 
 			for _, user := range users {
 				// Disregard bot users
@@ -161,25 +222,64 @@ func startBackgroundWorker() {
 				}
 
 				// Clear user's chat spam rate
-				if time.Now().UnixMilli()-user.GetSpammedChatLastTimeCleared() >= 10_000 {
+				if time.Now().UnixMilli()-user.GetSpammedChatLastTimeCleared() >= spamWindow {
 					user.ResetSpammedMessagesCount()
 					user.SetSpammedChatLastTimeCleared(time.Now().UnixMilli())
 				}
 
 				// Ping the user periodically
-				if time.Now().UnixMilli()-user.GetLastPingTimestamp() >= 40_000 {
+				if time.Now().UnixMilli()-user.GetLastPingTimestamp() >= timings.PingInterval.Duration().Milliseconds() {
 					sessions.SendPacketToUser(packets.NewServerPing(), user)
 					user.SetLastPingTimestamp()
 				}
 
+				// Mark the user as away if they haven't done anything in a while
+				if timings.AwayAfter > 0 {
+					user.CheckAway(timings.AwayAfter.Duration())
+				}
+
 				// User hasn't responded to pings in a while, so disconnect them
-				if time.Now().UnixMilli()-user.GetLastPongTimestamp() >= 120_000 {
+				if time.Now().UnixMilli()-user.GetLastPongTimestamp() >= timings.PongTimeout.Duration().Milliseconds() {
 					utils.CloseConnection(user.Conn)
 					log.Printf("[%v - %v] Disconnected due to being unresponsive to pings (timeout)\n", user.Info.Username, user.Info.Id)
 				}
 			}
 
+			sessions.UpdateOutboundQueueMetrics()
 			time.Sleep(500 * time.Millisecond)
 		}
 	}()
 }
+
+// Periodically records how many users are online and in each status, so activity can be viewed over time
+func startActivitySampler() {
+	go func() {
+		ticker := time.NewTicker(sessions.ActivitySampleInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			sessions.RecordActivitySample()
+		}
+	}()
+}
+
+// Periodically lifts temporary bans that have run out, so they don't keep showing as banned until the user logs in
+func startExpiredBanLifter() {
+	go func() {
+		ticker := time.NewTicker(expiredBanLiftInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			lifted, err := db.LiftExpiredBans()
+
+			if err != nil {
+				log.Printf("Failed to lift expired bans - %v\n", err)
+				continue
+			}
+
+			if lifted > 0 {
+				log.Printf("Lifted %v expired temporary bans\n", lifted)
+			}
+		}
+	}()
+}
@@ -25,7 +25,7 @@ func newClient(user *db.User) *Client {
 }
 
 func (client *Client) host() string {
-	return fmt.Sprintf("localhost:%v", config.Instance.Server.Port)
+	return fmt.Sprintf("localhost:%v", config.Get().Server.Port)
 }
 
 func (client *Client) loginData() handlers.LoginData {
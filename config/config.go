@@ -1,36 +1,126 @@
 package config
 
 import (
-	"encoding/json"
+	"example.com/Quaver/Z/common"
 	"fmt"
 	"log"
 	"os"
+	"sync/atomic"
+	"time"
 )
 
 type Configuration struct {
 	Server struct {
-		Port int `json:"port"`
+		Port                   int    `json:"port" env:"Z_SERVER_PORT"`
+		OutboundQueueSize      int    `json:"outbound_queue_size" env:"Z_SERVER_OUTBOUND_QUEUE_SIZE"`
+		OutboundOverflowPolicy string `json:"outbound_overflow_policy" env:"Z_SERVER_OUTBOUND_OVERFLOW_POLICY"`
+
+		// How often users are pinged
+		PingInterval Duration `json:"ping_interval" env:"Z_SERVER_PING_INTERVAL"`
+
+		// How long users can go without responding to pings before they are disconnected
+		PongTimeout Duration `json:"pong_timeout" env:"Z_SERVER_PONG_TIMEOUT"`
+
+		// How long users can go without any activity before they're marked as away. Zero disables it.
+		AwayAfter Duration `json:"away_after" env:"Z_SERVER_AWAY_AFTER"`
+
+		// How long the server keeps running after being asked to shut down, before the remaining users are logged out
+		DrainTimeout Duration `json:"drain_timeout" env:"Z_SERVER_DRAIN_TIMEOUT"`
+
+		// The ip addresses or CIDR ranges of the proxies in front of the server. X-Forwarded-For is only trusted
+		// when the connection comes from one of them.
+		TrustedProxies []string `json:"trusted_proxies"`
 	} `json:"server"`
 
-	BypassSteamLogin bool `json:"bypass_steam_login"`
+	Admin struct {
+		Port  int    `json:"port" env:"Z_ADMIN_PORT"`
+		Token string `json:"token" env:"Z_ADMIN_TOKEN"`
+	} `json:"admin"`
+
+	BypassSteamLogin bool `json:"bypass_steam_login" env:"Z_BYPASS_STEAM_LOGIN"`
+
+	Maintenance struct {
+		Enabled bool `json:"enabled" env:"Z_MAINTENANCE_ENABLED"`
+
+		// The user groups (a combination of common.UserGroups) that can still log in during maintenance
+		AllowedUserGroups int `json:"allowed_usergroups" env:"Z_MAINTENANCE_ALLOWED_USERGROUPS"`
+
+		// The notification users receive when they're unable to log in
+		Message string `json:"message" env:"Z_MAINTENANCE_MESSAGE"`
+	} `json:"maintenance"`
+
+	// Limits how quickly users are let in, so the server isn't overwhelmed when everyone reconnects after a restart
+	LoginAdmission struct {
+		MaxConcurrent int      `json:"max_concurrent" env:"Z_LOGIN_ADMISSION_MAX_CONCURRENT"`
+		Burst         int      `json:"burst" env:"Z_LOGIN_ADMISSION_BURST"`
+		PerSecond     float64  `json:"per_second" env:"Z_LOGIN_ADMISSION_PER_SECOND"`
+		QueueTimeout  Duration `json:"queue_timeout" env:"Z_LOGIN_ADMISSION_QUEUE_TIMEOUT"`
+	} `json:"login_admission"`
+
+	// Backs off clients that keep failing to log in, so they can't hammer the Steam API
+	LoginThrottle struct {
+		// The amount of failed logins that are allowed before backing off
+		FreeAttempts int      `json:"free_attempts" env:"Z_LOGIN_THROTTLE_FREE_ATTEMPTS"`
+		BaseBackoff  Duration `json:"base_backoff" env:"Z_LOGIN_THROTTLE_BASE_BACKOFF"`
+		MaxBackoff   Duration `json:"max_backoff" env:"Z_LOGIN_THROTTLE_MAX_BACKOFF"`
+
+		// How long without a failed login before the failures are forgotten
+		ResetAfter Duration `json:"reset_after" env:"Z_LOGIN_THROTTLE_RESET_AFTER"`
+
+		// The amount of failed logins from a single ip before an alert is sent
+		AlertThreshold int `json:"alert_threshold" env:"Z_LOGIN_THROTTLE_ALERT_THRESHOLD"`
+	} `json:"login_throttle"`
+
+	Compression struct {
+		Enabled   bool  `json:"enabled" env:"Z_COMPRESSION_ENABLED"`
+		Threshold int   `json:"threshold" env:"Z_COMPRESSION_THRESHOLD"`
+		PacketIds []int `json:"packet_ids"`
+	} `json:"compression"`
+
+	Chat struct {
+		// Messages longer than this are truncated
+		MaxMessageLength int `json:"max_message_length" env:"Z_CHAT_MAX_MESSAGE_LENGTH"`
+
+		// Users who send more than SpamMessageLimit messages within SpamWindow are muted for SpamMuteDuration
+		SpamWindow       Duration `json:"spam_window" env:"Z_CHAT_SPAM_WINDOW"`
+		SpamMessageLimit int      `json:"spam_message_limit" env:"Z_CHAT_SPAM_MESSAGE_LIMIT"`
+		SpamMuteDuration Duration `json:"spam_mute_duration" env:"Z_CHAT_SPAM_MUTE_DURATION"`
+	} `json:"chat"`
+
+	Multiplayer struct {
+		MinPlayers      int      `json:"min_players" env:"Z_MULTIPLAYER_MIN_PLAYERS"`
+		MaxPlayers      int      `json:"max_players" env:"Z_MULTIPLAYER_MAX_PLAYERS"`
+		CountdownLength Duration `json:"countdown_length" env:"Z_MULTIPLAYER_COUNTDOWN_LENGTH"`
+	} `json:"multiplayer"`
+
+	RateLimits struct {
+		// The amount of dropped packets within a minute before the user is disconnected
+		DisconnectThreshold int `json:"disconnect_threshold" env:"Z_RATE_LIMITS_DISCONNECT_THRESHOLD"`
+
+		Packets []struct {
+			Id        int     `json:"id"`
+			Burst     int     `json:"burst"`
+			PerSecond float64 `json:"per_second"`
+		} `json:"packets"`
+	} `json:"rate_limits"`
 
 	SQL struct {
-		Host     string `json:"host"`
-		Username string `json:"username"`
-		Password string `json:"password"`
-		Database string `json:"database"`
+		Host     string `json:"host" env:"Z_SQL_HOST"`
+		Username string `json:"username" env:"Z_SQL_USERNAME"`
+		Password string `json:"password" env:"Z_SQL_PASSWORD"`
+		Database string `json:"database" env:"Z_SQL_DATABASE"`
 	} `json:"sql"`
 
 	Redis struct {
-		Host     string `json:"host"`
-		Password string `json:"password"`
-		Database int    `json:"database"`
+		Host     string `json:"host" env:"Z_REDIS_HOST"`
+		Password string `json:"password" env:"Z_REDIS_PASSWORD"`
+		Database int    `json:"database" env:"Z_REDIS_DATABASE"`
 	}
 
 	Steam struct {
-		AppId        int    `json:"app_id"`
-		PublisherKey string `json:"publisher_key"`
-		APIKey       string `json:"api_key"`
+		AppId        int    `json:"app_id" env:"Z_STEAM_APP_ID"`
+		PublisherKey string `json:"publisher_key" env:"Z_STEAM_PUBLISHER_KEY"`
+		APIKey       string `json:"api_key" env:"Z_STEAM_API_KEY"`
 	} `json:"steam"`
 
 	DiscordWebhooks struct {
@@ -47,29 +137,145 @@ type Configuration struct {
 		AutoJoin       bool   `json:"auto_join"`
 		DiscordWebhook string `json:"discord_webhook"`
 		LimitedChat    bool   `json:"limited_chat"`
+
+		// The amount of previous messages users receive when they join the channel. Zero disables history.
+		HistoryLength int `json:"history_length"`
 	} `json:"chat_channels"`
 }
 
-var Instance *Configuration
+// The configuration that is currently in use. It is replaced as a whole on reload, so it can be read while
+// the config is being reloaded.
+var instance atomic.Pointer[Configuration]
+
+// MaxChatHistoryLength The most messages that can be sent in a single page of chat history
+const MaxChatHistoryLength = 100
+
+// Get Returns the configuration that is currently in use, or nil if it hasn't been loaded
+func Get() *Configuration {
+	return instance.Load()
+}
 
-// Load Parses the config file into Instance
+// Set Replaces the configuration that is currently in use
+func Set(config *Configuration) {
+	instance.Store(config)
+}
+
+// Load Parses the config file and sets it as the current configuration. Values that aren't in the file keep
+// their defaults, and environment variables take precedence over the file.
 func Load(path string) error {
-	if Instance != nil {
+	if Get() != nil {
 		return fmt.Errorf("config already loaded")
 	}
 
-	data, err := os.ReadFile(path)
+	config, err := read(path)
 
 	if err != nil {
 		return err
 	}
 
-	err = json.Unmarshal(data, &Instance)
+	if !instance.CompareAndSwap(nil, config) {
+		return fmt.Errorf("config already loaded")
+	}
+
+	log.Println("Config file has been successfully read")
+	return nil
+}
+
+// Reload Parses the config file again and replaces the current configuration. If the new config is invalid, the current one is kept.
+// Connections that are set up on startup (ports, SQL, Redis) aren't affected until the server is restarted.
+func Reload(path string) error {
+	config, err := read(path)
 
 	if err != nil {
 		return err
 	}
 
-	log.Println("Config file has been successfully read")
+	previous := instance.Swap(config)
+
+	if previous != nil && requiresRestart(previous, config) {
+		log.Println("Server port, admin port, SQL and Redis settings have changed and will take effect after a restart")
+	}
+
+	log.Println("Config file has been successfully reloaded")
 	return nil
 }
+
+// Reads, applies environment overrides to and validates a config file
+func read(path string) (*Configuration, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := Default()
+
+	err = decode(data, config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyEnvironment(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Returns if any of the settings that are only used on startup have changed
+func requiresRestart(current *Configuration, updated *Configuration) bool {
+	return current.Server.Port != updated.Server.Port ||
+		current.Admin.Port != updated.Admin.Port ||
+		current.SQL != updated.SQL ||
+		current.Redis != updated.Redis
+}
+
+// Default Returns a configuration with the default values
+func Default() *Configuration {
+	config := &Configuration{}
+
+	config.Server.OutboundQueueSize = 256
+	config.Server.OutboundOverflowPolicy = "disconnect"
+	config.Server.PingInterval = Duration(40 * time.Second)
+	config.Server.PongTimeout = Duration(120 * time.Second)
+	config.Server.AwayAfter = Duration(10 * time.Minute)
+	config.Server.DrainTimeout = Duration(10 * time.Second)
+
+	config.Maintenance.AllowedUserGroups = common.UserGroupAdmin | common.UserGroupDeveloper
+	config.Maintenance.Message = "The server is currently undergoing maintenance. Please try again later."
+
+	config.LoginAdmission.MaxConcurrent = 32
+	config.LoginAdmission.Burst = 50
+	config.LoginAdmission.PerSecond = 20
+	config.LoginAdmission.QueueTimeout = Duration(30 * time.Second)
+
+	config.LoginThrottle.FreeAttempts = 3
+	config.LoginThrottle.BaseBackoff = Duration(2 * time.Second)
+	config.LoginThrottle.MaxBackoff = Duration(15 * time.Minute)
+	config.LoginThrottle.ResetAfter = Duration(15 * time.Minute)
+	config.LoginThrottle.AlertThreshold = 25
+
+	config.Compression.Threshold = 512
+
+	config.Chat.MaxMessageLength = 500
+	config.Chat.SpamWindow = Duration(10 * time.Second)
+	config.Chat.SpamMessageLimit = 10
+	config.Chat.SpamMuteDuration = Duration(30 * time.Minute)
+
+	config.Multiplayer.MinPlayers = 2
+	config.Multiplayer.MaxPlayers = 16
+	config.Multiplayer.CountdownLength = Duration(5 * time.Second)
+
+	config.RateLimits.DisconnectThreshold = 50
+
+	return config
+}
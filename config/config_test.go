@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadAppliesDefaultsAndEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, []byte(`{"server": {"port": 3000, "ping_interval": "20s"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("Z_MULTIPLAYER_MAX_PLAYERS", "8")
+	t.Setenv("Z_CHAT_SPAM_MUTE_DURATION", "5m")
+
+	previous := Get()
+	Set(nil)
+	defer func() { Set(previous) }()
+
+	if err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if Get().Server.PingInterval.Duration() != 20*time.Second {
+		t.Fatalf("expected ping interval from the file, got %v", Get().Server.PingInterval.Duration())
+	}
+
+	if Get().Server.PongTimeout.Duration() != 120*time.Second {
+		t.Fatalf("expected default pong timeout, got %v", Get().Server.PongTimeout.Duration())
+	}
+
+	if Get().Multiplayer.MaxPlayers != 8 {
+		t.Fatalf("expected max players from the environment, got %v", Get().Multiplayer.MaxPlayers)
+	}
+
+	if Get().Chat.SpamMuteDuration.Duration() != 5*time.Minute {
+		t.Fatalf("expected spam mute duration from the environment, got %v", Get().Chat.SpamMuteDuration.Duration())
+	}
+}
+
+func TestValidateReportsEveryInvalidField(t *testing.T) {
+	config := Default()
+	config.Server.Port = 3000
+	config.Server.OutboundOverflowPolicy = "explode"
+	config.Multiplayer.MinPlayers = 10
+	config.Multiplayer.MaxPlayers = 4
+	config.Chat.MaxMessageLength = 0
+
+	err := config.Validate()
+
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+
+	for _, field := range []string{"server.outbound_overflow_policy", "multiplayer.max_players", "chat.max_message_length"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Fatalf("expected an error for %v, got %v", field, err)
+		}
+	}
+
+	config = Default()
+	config.Server.Port = 3000
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected the default config to be valid, got %v", err)
+	}
+}
+
+func TestInvalidEnvironmentValue(t *testing.T) {
+	t.Setenv("Z_SERVER_PORT", "abc")
+
+	err := applyEnvironment(Default())
+
+	if err == nil || !strings.Contains(err.Error(), "Z_SERVER_PORT") {
+		t.Fatalf("expected an error for Z_SERVER_PORT, got %v", err)
+	}
+}
+
+func TestReloadKeepsConfigWhenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, []byte(`{"server": {"port": 3000}, "multiplayer": {"max_players": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := Get()
+	defer func() { Set(previous) }()
+
+	current := Default()
+	Set(current)
+
+	if err := Reload(path); err == nil {
+		t.Fatal("expected an invalid config to fail to reload")
+	}
+
+	if Get() != current {
+		t.Fatal("expected the current config to be kept")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"server": {"port": 3000}, "multiplayer": {"max_players": 8}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Reload(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if Get().Multiplayer.MaxPlayers != 8 {
+		t.Fatalf("expected reloaded max players, got %v", Get().Multiplayer.MaxPlayers)
+	}
+}
+
+func TestValidateChatHistoryLength(t *testing.T) {
+	config := Default()
+	config.Server.Port = 3000
+
+	err := json.Unmarshal([]byte(`{"chat_channels": [{"name": "#quaver", "history_length": 500}]}`), config)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "chat_channels[0].history_length") {
+		t.Fatalf("expected an error for the history length, got %v", err)
+	}
+}
+
+func TestRequiresRestart(t *testing.T) {
+	current := Default()
+	updated := Default()
+	updated.Admin.Token = "updated"
+
+	if requiresRestart(current, updated) {
+		t.Fatal("expected the admin token to be reloaded without a restart")
+	}
+
+	updated.Admin.Port = current.Admin.Port + 1
+
+	if !requiresRestart(current, updated) {
+		t.Fatal("expected a changed admin port to require a restart")
+	}
+}
+
+func TestReadReportsEveryTypeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"server": {"port": "3000", "ping_interval": 20}, "multiplayer": {"max_players": "8"}, "redis": []}`
+
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := read(path)
+
+	if err == nil {
+		t.Fatal("expected the config to fail to read")
+	}
+
+	for _, field := range []string{"server.port", "server.ping_interval", "multiplayer.max_players", "Redis"} {
+		if !strings.Contains(err.Error(), field+":") {
+			t.Fatalf("expected an error for %v, got %v", field, err)
+		}
+	}
+}
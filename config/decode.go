@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Decodes a config file field by field, so every field with the wrong type is reported instead of only the first one
+func decode(data []byte, config *Configuration) error {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	return decodeStruct(fields, reflect.ValueOf(config).Elem(), "")
+}
+
+// Walks through a struct and decodes each of its fields from the matching JSON value
+func decodeStruct(fields map[string]json.RawMessage, value reflect.Value, path string) error {
+	var errs []error
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		fieldType := value.Type().Field(i)
+		name, _, _ := strings.Cut(fieldType.Tag.Get("json"), ",")
+
+		if !fieldType.IsExported() || name == "-" {
+			continue
+		}
+
+		// Fields without a JSON name are matched by their Go name, the same as encoding/json does
+		if name == "" {
+			name = fieldType.Name
+		}
+
+		data, ok := lookupField(fields, name)
+
+		if !ok {
+			continue
+		}
+
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if field.Kind() == reflect.Struct {
+			var nested map[string]json.RawMessage
+
+			if err := json.Unmarshal(data, &nested); err != nil {
+				errs = append(errs, fmt.Errorf("%v: must be an object", name))
+				continue
+			}
+
+			errs = append(errs, decodeStruct(nested, field, name))
+			continue
+		}
+
+		if err := json.Unmarshal(data, field.Addr().Interface()); err != nil {
+			errs = append(errs, describeDecodeError(name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Returns the JSON value for a field. Like encoding/json, an exact match is preferred over a case-insensitive one.
+func lookupField(fields map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if data, ok := fields[name]; ok {
+		return data, true
+	}
+
+	for key, data := range fields {
+		if strings.EqualFold(key, name) {
+			return data, true
+		}
+	}
+
+	return nil, false
+}
+
+// Returns a decoding error prefixed with the name of the field it happened in
+func describeDecodeError(name string, err error) error {
+	var typeErr *json.UnmarshalTypeError
+
+	if errors.As(err, &typeErr) {
+		if typeErr.Field != "" {
+			name += "." + typeErr.Field
+		}
+
+		return fmt.Errorf("%v: must be %v, got %v", name, typeErr.Type, typeErr.Value)
+	}
+
+	return fmt.Errorf("%v: %v", name, err)
+}
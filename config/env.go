@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// Overrides config values with the environment variables named in their `env` tags
+func applyEnvironment(config *Configuration) error {
+	return applyEnvironmentToStruct(reflect.ValueOf(config).Elem())
+}
+
+// Walks through a struct and sets the fields that have an environment variable set
+func applyEnvironmentToStruct(value reflect.Value) error {
+	var errs []error
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		fieldType := value.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			errs = append(errs, applyEnvironmentToStruct(field))
+			continue
+		}
+
+		name := fieldType.Tag.Get("env")
+
+		if name == "" {
+			continue
+		}
+
+		env, ok := os.LookupEnv(name)
+
+		if !ok {
+			continue
+		}
+
+		if err := setFieldFromString(field, env); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Parses a string into a field of any of the types used in the config
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		parsed, err := time.ParseDuration(value)
+
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(parsed))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type())
+	}
+
+	return nil
+}
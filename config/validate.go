@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Validate Checks every field of the config and returns all the invalid ones at once
+func (config *Configuration) Validate() error {
+	var errs []error
+
+	check := func(valid bool, field string, format string, args ...interface{}) {
+		if !valid {
+			errs = append(errs, fmt.Errorf("%v: %v", field, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(config.Server.Port > 0 && config.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %v", config.Server.Port)
+	check(config.Server.OutboundQueueSize > 0, "server.outbound_queue_size", "must be greater than 0, got %v", config.Server.OutboundQueueSize)
+	check(config.Server.OutboundOverflowPolicy == "drop" || config.Server.OutboundOverflowPolicy == "disconnect",
+		"server.outbound_overflow_policy", "must be \"drop\" or \"disconnect\", got %q", config.Server.OutboundOverflowPolicy)
+	check(config.Server.PingInterval > 0, "server.ping_interval", "must be greater than 0")
+	check(config.Server.PongTimeout > config.Server.PingInterval, "server.pong_timeout", "must be longer than server.ping_interval")
+	check(config.Server.AwayAfter >= 0, "server.away_after", "must not be negative")
+	check(config.Server.DrainTimeout >= 0, "server.drain_timeout", "must not be negative")
+
+	for i, proxy := range config.Server.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, fmt.Sprintf("server.trusted_proxies[%v]", i), "must be an ip address or CIDR range, got %q", proxy)
+	}
+
+	check(config.Admin.Port >= 0 && config.Admin.Port <= 65535, "admin.port", "must be between 0 and 65535, got %v", config.Admin.Port)
+	check(config.Admin.Port == 0 || config.Admin.Port != config.Server.Port, "admin.port", "must be different from server.port")
+
+	check(config.Maintenance.Message != "", "maintenance.message", "must not be empty")
+
+	check(config.LoginAdmission.MaxConcurrent > 0, "login_admission.max_concurrent", "must be greater than 0, got %v", config.LoginAdmission.MaxConcurrent)
+	check(config.LoginAdmission.Burst > 0, "login_admission.burst", "must be greater than 0, got %v", config.LoginAdmission.Burst)
+	check(config.LoginAdmission.PerSecond > 0, "login_admission.per_second", "must be greater than 0, got %v", config.LoginAdmission.PerSecond)
+	check(config.LoginAdmission.QueueTimeout > 0, "login_admission.queue_timeout", "must be greater than 0")
+
+	check(config.LoginThrottle.FreeAttempts >= 0, "login_throttle.free_attempts", "must not be negative, got %v", config.LoginThrottle.FreeAttempts)
+	check(config.LoginThrottle.BaseBackoff > 0, "login_throttle.base_backoff", "must be greater than 0")
+	check(config.LoginThrottle.MaxBackoff >= config.LoginThrottle.BaseBackoff, "login_throttle.max_backoff", "must be at least login_throttle.base_backoff")
+	check(config.LoginThrottle.ResetAfter > 0, "login_throttle.reset_after", "must be greater than 0")
+	check(config.LoginThrottle.AlertThreshold > 0, "login_throttle.alert_threshold", "must be greater than 0, got %v", config.LoginThrottle.AlertThreshold)
+
+	check(config.Compression.Threshold >= 0, "compression.threshold", "must not be negative, got %v", config.Compression.Threshold)
+
+	check(config.Chat.MaxMessageLength > 0, "chat.max_message_length", "must be greater than 0, got %v", config.Chat.MaxMessageLength)
+	check(config.Chat.SpamWindow > 0, "chat.spam_window", "must be greater than 0")
+	check(config.Chat.SpamMessageLimit > 0, "chat.spam_message_limit", "must be greater than 0, got %v", config.Chat.SpamMessageLimit)
+	check(config.Chat.SpamMuteDuration > 0, "chat.spam_mute_duration", "must be greater than 0")
+
+	check(config.Multiplayer.MinPlayers >= 2, "multiplayer.min_players", "must be at least 2, got %v", config.Multiplayer.MinPlayers)
+	check(config.Multiplayer.MaxPlayers >= config.Multiplayer.MinPlayers, "multiplayer.max_players", "must be at least multiplayer.min_players, got %v", config.Multiplayer.MaxPlayers)
+	check(config.Multiplayer.CountdownLength > 0, "multiplayer.countdown_length", "must be greater than 0")
+
+	check(config.RateLimits.DisconnectThreshold > 0, "rate_limits.disconnect_threshold", "must be greater than 0, got %v", config.RateLimits.DisconnectThreshold)
+
+	for i, limit := range config.RateLimits.Packets {
+		field := fmt.Sprintf("rate_limits.packets[%v]", i)
+
+		check(limit.Burst > 0, field+".burst", "must be greater than 0, got %v", limit.Burst)
+		check(limit.PerSecond > 0, field+".per_second", "must be greater than 0, got %v", limit.PerSecond)
+	}
+
+	for i, channel := range config.ChatChannels {
+		check(len(channel.Name) > 1 && channel.Name[0] == '#', fmt.Sprintf("chat_channels[%v].name", i), "must start with #, got %q", channel.Name)
+		check(channel.HistoryLength >= 0 && channel.HistoryLength <= MaxChatHistoryLength, fmt.Sprintf("chat_channels[%v].history_length", i),
+			"must be between 0 and %v, got %v", MaxChatHistoryLength, channel.HistoryLength)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"database/sql"
+	"net"
+	"strings"
+	"time"
+)
+
+// BannedIp An ip address or CIDR range that isn't allowed to log in
+type BannedIp struct {
+	Id        int           `db:"id"`
+	Ip        string        `db:"ip"`
+	UserId    sql.NullInt32 `db:"user_id"`
+	Reason    string        `db:"reason"`
+	Timestamp int64         `db:"timestamp"`
+}
+
+type HardwareIdType string
+
+const (
+	HardwareIdCpu     HardwareIdType = "cpu"
+	HardwareIdDisk    HardwareIdType = "disk"
+	HardwareIdCpuDisk HardwareIdType = "cpu_disk"
+)
+
+// BannedHardwareId A hardware id of a machine that isn't allowed to log in
+type BannedHardwareId struct {
+	Id         int            `db:"id"`
+	Type       HardwareIdType `db:"type"`
+	HardwareId string         `db:"hardware_id"`
+	UserId     sql.NullInt32  `db:"user_id"`
+	Reason     string         `db:"reason"`
+	Timestamp  int64          `db:"timestamp"`
+}
+
+// GetIpBan Returns the ban that matches an ip address, either directly or by its CIDR range.
+// Returns sql.ErrNoRows if the ip isn't banned.
+func GetIpBan(ip string) (*BannedIp, error) {
+	parsed := net.ParseIP(ip)
+
+	if parsed == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	var ban BannedIp
+
+	err := SQL.Get(&ban, "SELECT * FROM banned_ips WHERE ip IN (?, ?) LIMIT 1", ip, parsed.String())
+
+	if err == nil {
+		return &ban, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Ranges can't be matched in the query, so only those are checked here
+	var ranges []*BannedIp
+
+	err = SQL.Select(&ranges, "SELECT * FROM banned_ips WHERE ip LIKE '%/%'")
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ban := range ranges {
+		if ipMatchesBan(parsed, ban.Ip) {
+			return ban, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// InsertIpBan Bans an ip address or CIDR range
+func InsertIpBan(ip string, userId int, reason string) error {
+	_, err := SQL.Exec("INSERT INTO banned_ips (ip, user_id, reason, timestamp) VALUES (?, ?, ?, ?)",
+		ip, userId, reason, time.Now().UnixMilli())
+
+	return err
+}
+
+// GetHardwareIdBan Returns the ban that matches any of a machine's hardware ids.
+// Returns sql.ErrNoRows if none of them are banned.
+func GetHardwareIdBan(cpuId string, diskId string, cpuDiskId string) (*BannedHardwareId, error) {
+	var conditions []string
+	var args []interface{}
+
+	for hardwareType, hardwareId := range map[HardwareIdType]string{HardwareIdCpu: cpuId, HardwareIdDisk: diskId, HardwareIdCpuDisk: cpuDiskId} {
+		// Clients that don't send a hardware id have it set to "none", which would match every one of them
+		if hardwareId == "" || hardwareId == "none" {
+			continue
+		}
+
+		conditions = append(conditions, "(type = ? AND hardware_id = ?)")
+		args = append(args, hardwareType, hardwareId)
+	}
+
+	if len(conditions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	var ban BannedHardwareId
+
+	err := SQL.Get(&ban, "SELECT * FROM banned_hardware_ids WHERE "+strings.Join(conditions, " OR ")+" LIMIT 1", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ban, nil
+}
+
+// InsertHardwareIdBan Bans a hardware id
+func InsertHardwareIdBan(hardwareType HardwareIdType, hardwareId string, userId int, reason string) error {
+	_, err := SQL.Exec("INSERT INTO banned_hardware_ids (type, hardware_id, user_id, reason, timestamp) VALUES (?, ?, ?, ?, ?)",
+		hardwareType, hardwareId, userId, reason, time.Now().UnixMilli())
+
+	return err
+}
+
+// Returns if an ip is the banned ip, or is within the banned CIDR range
+func ipMatchesBan(ip net.IP, ban string) bool {
+	if strings.Contains(ban, "/") {
+		_, network, err := net.ParseCIDR(ban)
+		return err == nil && network.Contains(ip)
+	}
+
+	banned := net.ParseIP(ban)
+	return banned != nil && banned.Equal(ip)
+}
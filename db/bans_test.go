@@ -0,0 +1,27 @@
+package db
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIpMatchesBan(t *testing.T) {
+	tests := []struct {
+		ip       string
+		ban      string
+		expected bool
+	}{
+		{"192.168.1.1", "192.168.1.1", true},
+		{"192.168.1.2", "192.168.1.1", false},
+		{"192.168.1.200", "192.168.1.0/24", true},
+		{"192.168.2.1", "192.168.1.0/24", false},
+		{"2001:db8::1", "2001:db8::/32", true},
+		{"192.168.1.1", "not an ip", false},
+	}
+
+	for _, test := range tests {
+		if ipMatchesBan(net.ParseIP(test.ip), test.ban) != test.expected {
+			t.Fatalf("expected %v matching %v to be %v", test.ip, test.ban, test.expected)
+		}
+	}
+}
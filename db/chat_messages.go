@@ -1,6 +1,9 @@
 package db
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type ChatMessageType int
 
@@ -9,6 +12,45 @@ const (
 	ChatMessageTypePrivate
 )
 
+type ChatMessage struct {
+	Id                    int            `db:"id"`
+	SenderId              int            `db:"sender_id"`
+	SenderName            string         `db:"sender_name"`
+	SenderClanTag         sql.NullString `db:"sender_clan_tag"`
+	SenderClanAccentColor sql.NullString `db:"sender_clan_accent_color"`
+	Message               string         `db:"message"`
+	Timestamp             int64          `db:"timestamp"`
+}
+
+// GetPublicChatMessages Retrieves the latest messages that were sent in a channel, newest first.
+// Only messages with an id lower than before are included, unless before is zero.
+func GetPublicChatMessages(channel string, before int, limit int) ([]*ChatMessage, error) {
+	query := "SELECT chat_messages.id, chat_messages.sender_id, COALESCE(users.username, '') AS sender_name, " +
+		"clans.tag AS sender_clan_tag, clans.accent_color AS sender_clan_accent_color, chat_messages.message, chat_messages.timestamp " +
+		"FROM chat_messages LEFT JOIN users ON users.id = chat_messages.sender_id LEFT JOIN clans ON users.clan_id = clans.id " +
+		"WHERE chat_messages.type = ? AND chat_messages.channel = ?"
+
+	args := []interface{}{ChatMessageTypePublic, channel}
+
+	if before > 0 {
+		query += " AND chat_messages.id < ?"
+		args = append(args, before)
+	}
+
+	query += " ORDER BY chat_messages.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	messages := make([]*ChatMessage, 0)
+
+	err := SQL.Select(&messages, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
 // InsertPublicChatMessage Inserts a public chat message into the database
 func InsertPublicChatMessage(senderId int, channel string, message string) error {
 	err := InsertChatMessage(senderId, ChatMessageTypePublic, -1, channel, message)
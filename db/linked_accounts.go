@@ -0,0 +1,101 @@
+package db
+
+// How much each shared login fingerprint contributes to the likelihood of two accounts belonging to the same person
+const (
+	LinkedAccountScoreCpuDiskId = 3
+	LinkedAccountScoreDiskId    = 2
+	LinkedAccountScoreIp        = 1
+)
+
+// LinkedAccount Another account that has logged in with the same hardware or from the same ip as a user
+type LinkedAccount struct {
+	UserId          int    `db:"user_id"`
+	Username        string `db:"username"`
+	Allowed         bool   `db:"allowed"`
+	SharedCpuDiskId bool   `db:"shared_cpu_disk_id"`
+	SharedDiskId    bool   `db:"shared_disk_id"`
+	SharedIp        bool   `db:"shared_ip"`
+}
+
+// GetLinkedAccounts Returns every account that shares a CPU + disk id, disk id or ip address with a user
+func GetLinkedAccounts(userId int) ([]*LinkedAccount, error) {
+	var hardware []*LinkedAccount
+
+	hardwareQuery := "SELECT other.user_id, users.username, users.allowed, " +
+		"MAX(other.cpu_disk_id = mine.cpu_disk_id) AS shared_cpu_disk_id, MAX(other.disk_id = mine.disk_id) AS shared_disk_id, FALSE AS shared_ip " +
+		"FROM login_hardware_ids mine " +
+		"INNER JOIN login_hardware_ids other ON other.user_id != mine.user_id AND " +
+		"((other.cpu_disk_id = mine.cpu_disk_id AND mine.cpu_disk_id != 'none') OR (other.disk_id = mine.disk_id AND mine.disk_id != 'none')) " +
+		"INNER JOIN users ON users.id = other.user_id " +
+		"WHERE mine.user_id = ? GROUP BY other.user_id, users.username, users.allowed"
+
+	err := SQL.Select(&hardware, hardwareQuery, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []*LinkedAccount
+
+	ipQuery := "SELECT DISTINCT other.user_id, users.username, users.allowed, FALSE AS shared_cpu_disk_id, FALSE AS shared_disk_id, TRUE AS shared_ip " +
+		"FROM login_ips mine " +
+		"INNER JOIN login_ips other ON other.ip = mine.ip AND other.user_id != mine.user_id " +
+		"INNER JOIN users ON users.id = other.user_id " +
+		"WHERE mine.user_id = ?"
+
+	err = SQL.Select(&ips, ipQuery, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeLinkedAccounts(hardware, ips), nil
+}
+
+// Score Returns how likely it is that the account belongs to the same person
+func (account *LinkedAccount) Score() int {
+	score := 0
+
+	if account.SharedCpuDiskId {
+		score += LinkedAccountScoreCpuDiskId
+	}
+
+	if account.SharedDiskId {
+		score += LinkedAccountScoreDiskId
+	}
+
+	if account.SharedIp {
+		score += LinkedAccountScoreIp
+	}
+
+	return score
+}
+
+// GetProfileUrl Returns the url of the linked account's profile
+func (account *LinkedAccount) GetProfileUrl() string {
+	return (&User{Id: account.UserId}).GetProfileUrl()
+}
+
+// Combines the accounts found by hardware ids and ip addresses, so each account is only listed once
+func mergeLinkedAccounts(lists ...[]*LinkedAccount) []*LinkedAccount {
+	merged := make([]*LinkedAccount, 0)
+	byUserId := map[int]*LinkedAccount{}
+
+	for _, list := range lists {
+		for _, account := range list {
+			existing, ok := byUserId[account.UserId]
+
+			if !ok {
+				byUserId[account.UserId] = account
+				merged = append(merged, account)
+				continue
+			}
+
+			existing.SharedCpuDiskId = existing.SharedCpuDiskId || account.SharedCpuDiskId
+			existing.SharedDiskId = existing.SharedDiskId || account.SharedDiskId
+			existing.SharedIp = existing.SharedIp || account.SharedIp
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,33 @@
+package db
+
+import "testing"
+
+func TestMergeLinkedAccounts(t *testing.T) {
+	hardware := []*LinkedAccount{
+		{UserId: 2, SharedCpuDiskId: true, SharedDiskId: true},
+		{UserId: 3, SharedDiskId: true},
+	}
+
+	ips := []*LinkedAccount{
+		{UserId: 2, SharedIp: true},
+		{UserId: 4, SharedIp: true},
+	}
+
+	merged := mergeLinkedAccounts(hardware, ips)
+
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 linked accounts, got %v", len(merged))
+	}
+
+	expectedScores := map[int]int{
+		2: LinkedAccountScoreCpuDiskId + LinkedAccountScoreDiskId + LinkedAccountScoreIp,
+		3: LinkedAccountScoreDiskId,
+		4: LinkedAccountScoreIp,
+	}
+
+	for _, account := range merged {
+		if account.Score() != expectedScores[account.UserId] {
+			t.Fatalf("expected user #%v to have a score of %v, got %v", account.UserId, expectedScores[account.UserId], account.Score())
+		}
+	}
+}
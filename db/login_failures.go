@@ -0,0 +1,27 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+type LoginFailure struct {
+	Id        int           `db:"id"`
+	Ip        string        `db:"ip"`
+	SteamId   string        `db:"steam_id"`
+	UserId    sql.NullInt32 `db:"user_id"`
+	Reason    string        `db:"reason"`
+	Timestamp int64         `db:"timestamp"`
+}
+
+// InsertLoginFailure Logs a failed login and the reason it failed in the database
+func InsertLoginFailure(ip string, steamId string, userId int, reason string) error {
+	_, err := SQL.Exec("INSERT INTO login_failures (ip, steam_id, user_id, reason, timestamp) VALUES (?, ?, ?, ?, ?)",
+		ip, steamId, sql.NullInt32{Int32: int32(userId), Valid: userId != 0}, reason, time.Now().UnixMilli())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -8,7 +8,7 @@ import (
 func TestInsertLoginIpAddress(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -9,7 +9,7 @@ import (
 func TestFetchProcesses(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// RecentPlayerType How two users recently played together
+type RecentPlayerType int
+
+const (
+	RecentPlayerMultiplayer RecentPlayerType = iota
+	RecentPlayerSpectator
+)
+
+type RecentPlayer struct {
+	UserId    int              `db:"target_user_id"`
+	Username  string           `db:"username"`
+	Type      RecentPlayerType `db:"type"`
+	GameName  sql.NullString   `db:"game_name"`
+	Timestamp int64            `db:"timestamp"`
+}
+
+// GetRecentPlayers Retrieves the users that a user has most recently played with
+func GetRecentPlayers(userId int, limit int) ([]*RecentPlayer, error) {
+	const query string = "SELECT user_recent_players.target_user_id, users.username, user_recent_players.type, user_recent_players.game_name, user_recent_players.timestamp " +
+		"FROM user_recent_players INNER JOIN users ON users.id = user_recent_players.target_user_id " +
+		"WHERE user_recent_players.user_id = ? ORDER BY user_recent_players.timestamp DESC LIMIT ?"
+
+	players := make([]*RecentPlayer, 0)
+
+	err := SQL.Select(&players, query, userId, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return players, nil
+}
+
+// InsertRecentPlayers Records that every user in a list has played with each other.
+// Users who have already played together have their game and time updated.
+func InsertRecentPlayers(userIds []int, recentType RecentPlayerType, gameName string) error {
+	name := sql.NullString{String: gameName, Valid: gameName != ""}
+	timestamp := time.Now().UnixMilli()
+
+	var values []string
+	var args []interface{}
+
+	for _, userId := range userIds {
+		for _, targetUserId := range userIds {
+			if userId == targetUserId {
+				continue
+			}
+
+			values = append(values, "(?, ?, ?, ?, ?)")
+			args = append(args, userId, targetUserId, recentType, name, timestamp)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO user_recent_players (user_id, target_user_id, type, game_name, timestamp) VALUES " + strings.Join(values, ", ") +
+		" ON DUPLICATE KEY UPDATE type = VALUES(type), game_name = VALUES(game_name), timestamp = VALUES(timestamp)"
+
+	_, err := SQL.Exec(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
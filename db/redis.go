@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"errors"
 	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
 	"github.com/go-redis/redis/v8"
 	"log"
 	"time"
@@ -10,6 +12,7 @@ import (
 
 var (
 	Redis                            *redis.Client
+	redisSubscriber                  *redis.PubSub
 	RedisCtx                         = context.Background()
 	redisChannelHandlers             = map[string][]func(message *redis.Message){}
 	RedisChannelSongRequests         = "quaver:song_requests"
@@ -27,9 +30,9 @@ func InitializeRedis() {
 	}
 
 	Redis = redis.NewClient(&redis.Options{
-		Addr:         config.Instance.Redis.Host,
-		Password:     config.Instance.Redis.Password,
-		DB:           config.Instance.Redis.Database,
+		Addr:         config.Get().Redis.Host,
+		Password:     config.Get().Redis.Password,
+		DB:           config.Get().Redis.Database,
 		ReadTimeout:  time.Minute,
 		WriteTimeout: time.Minute,
 	})
@@ -44,24 +47,52 @@ func InitializeRedis() {
 	sub := Redis.Subscribe(RedisCtx, RedisChannelSongRequests, RedisChannelTwitchConnection, RedisChannelMultiplayerMapShares, RedisChannelFirstPlaceScores, RedisChannelRankedClanMap,
 		RedisChannelClanFirstPlace)
 
+	redisSubscriber = sub
+
 	go func() {
 		for {
 			msg, err := sub.ReceiveMessage(RedisCtx)
 
 			if err != nil {
 				log.Printf("Error receiving redis message - %v", err)
+				continue
 			}
 
+			start := time.Now()
+
+			metrics.RedisMessages.WithLabelValues(msg.Channel).Inc()
+			metrics.RedisLastMessageTimestamp.Set(float64(start.Unix()))
+
 			// Go through and call all the handler functions for this particular channel.
 			if handlers, ok := redisChannelHandlers[msg.Channel]; ok {
 				for _, handler := range handlers {
 					handler(msg)
 				}
 			}
+
+			metrics.RedisHandlerDuration.WithLabelValues(msg.Channel).Observe(time.Since(start).Seconds())
 		}
 	}()
 }
 
+// PingRedis Checks if the redis connection is healthy
+func PingRedis(ctx context.Context) error {
+	if Redis == nil {
+		return errors.New("redis is not initialized")
+	}
+
+	return Redis.Ping(ctx).Err()
+}
+
+// PingRedisSubscriber Checks if the connection used for subscribing to redis channels is healthy
+func PingRedisSubscriber(ctx context.Context) error {
+	if redisSubscriber == nil {
+		return errors.New("redis subscriber is not initialized")
+	}
+
+	return redisSubscriber.Ping(ctx)
+}
+
 // AddRedisSubscriberHandler Adds a handler to a given channel
 func AddRedisSubscriberHandler(channel string, f func(message *redis.Message)) {
 	if _, ok := redisChannelHandlers[channel]; !ok {
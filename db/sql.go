@@ -1,7 +1,11 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
@@ -12,7 +16,12 @@ type RowScanner interface {
 	Scan(dest ...interface{}) error
 }
 
-var SQL *sqlx.DB
+// Database A database connection that keeps track of failed queries
+type Database struct {
+	*sqlx.DB
+}
+
+var SQL *Database
 
 // InitializeSQL Initializes the SQL database connection
 func InitializeSQL() {
@@ -20,7 +29,7 @@ func InitializeSQL() {
 		return
 	}
 
-	credentials := config.Instance.SQL
+	credentials := config.Get().SQL
 	connStr := fmt.Sprintf("%v:%v@tcp(%v)/%v", credentials.Username, credentials.Password, credentials.Host, credentials.Database)
 
 	db, err := sqlx.Connect("mysql", connStr)
@@ -35,7 +44,7 @@ func InitializeSQL() {
 		log.Fatalln(err)
 	}
 
-	SQL = db
+	SQL = &Database{DB: db}
 
 	SQL.DB.SetMaxOpenConns(100)
 	SQL.DB.SetMaxIdleConns(10)
@@ -59,3 +68,37 @@ func CloseSQLConnection() {
 	log.Println("SQL database connection has been closed")
 	SQL = nil
 }
+
+// PingSQL Checks if the SQL database connection is healthy
+func PingSQL(ctx context.Context) error {
+	if SQL == nil {
+		return errors.New("sql is not initialized")
+	}
+
+	return SQL.PingContext(ctx)
+}
+
+// Get Runs a query and scans the first row into dest
+func (db *Database) Get(dest interface{}, query string, args ...interface{}) error {
+	return recordSQLError("get", db.DB.Get(dest, query, args...))
+}
+
+// Select Runs a query and scans every row into dest
+func (db *Database) Select(dest interface{}, query string, args ...interface{}) error {
+	return recordSQLError("select", db.DB.Select(dest, query, args...))
+}
+
+// Exec Runs a query without returning any rows
+func (db *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
+	result, err := db.DB.Exec(query, args...)
+	return result, recordSQLError("exec", err)
+}
+
+// Counts failed queries. Queries that don't return any rows aren't counted, as callers use them to check for existence.
+func recordSQLError(operation string, err error) error {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		metrics.SQLErrors.WithLabelValues(operation).Inc()
+	}
+
+	return err
+}
@@ -2,6 +2,21 @@ package db
 
 import "database/sql"
 
+// The bits of a relationship a user has with another user
+const (
+	// RelationshipFriend The user has added the other user as a friend. Users are only friends if both of them have it set.
+	RelationshipFriend = 1 << iota
+
+	// RelationshipPending The user has sent a friend request that hasn't been answered yet
+	RelationshipPending
+
+	// RelationshipDeclined The user's friend request has been declined
+	RelationshipDeclined
+
+	// RelationshipBlocked The user has blocked the other user. It replaces any other relationship between them.
+	RelationshipBlocked
+)
+
 type UserRelationship struct {
 	Id           int `db:"id"`
 	UserId       int `db:"user_id"`
@@ -9,13 +24,26 @@ type UserRelationship struct {
 	Relationship int `db:"relationship"`
 }
 
-// GetUserFriendsList Retrieves a slice of user ids that a given user is friends with
+// FriendRequest A user that has sent a friend request
+type FriendRequest struct {
+	UserId   int    `db:"user_id"`
+	Username string `db:"username"`
+}
+
+// Has Returns if the relationship has a given bit set
+func (r *UserRelationship) Has(relationship int) bool {
+	return r != nil && r.Relationship&relationship != 0
+}
+
+// GetUserFriendsList Retrieves a slice of user ids that a given user is mutual friends with
 func GetUserFriendsList(userId int) ([]int, error) {
-	const query string = "SELECT target_user_id FROM user_relationships WHERE user_id = ? AND (relationship & 1) != 0"
+	const query string = "SELECT mine.target_user_id FROM user_relationships mine " +
+		"INNER JOIN user_relationships theirs ON theirs.user_id = mine.target_user_id AND theirs.target_user_id = mine.user_id " +
+		"WHERE mine.user_id = ? AND (mine.relationship & ?) != 0 AND (theirs.relationship & ?) != 0"
 
 	relationships := make([]int, 0)
 
-	err := SQL.Select(&relationships, query, userId)
+	err := SQL.Select(&relationships, query, userId, RelationshipFriend, RelationshipFriend)
 
 	if err != nil {
 		return nil, err
@@ -24,6 +52,57 @@ func GetUserFriendsList(userId int) ([]int, error) {
 	return relationships, nil
 }
 
+// ConvertLegacyFriendsToRequests Turns friends that were only added by one of the users, from before friend requests
+// existed, into pending friend requests. Those aren't mutual, so they'd otherwise disappear from friends lists.
+// Returns the amount of converted friends.
+func ConvertLegacyFriendsToRequests() (int64, error) {
+	const query string = "UPDATE user_relationships mine " +
+		"LEFT JOIN user_relationships theirs ON theirs.user_id = mine.target_user_id AND theirs.target_user_id = mine.user_id " +
+		"AND (theirs.relationship & ?) != 0 " +
+		"SET mine.relationship = ? " +
+		"WHERE (mine.relationship & ?) != 0 AND theirs.id IS NULL"
+
+	result, err := SQL.Exec(query, RelationshipFriend|RelationshipBlocked, RelationshipPending, RelationshipFriend)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetPendingFriendRequests Retrieves the users that have sent a friend request to a given user
+func GetPendingFriendRequests(userId int) ([]*FriendRequest, error) {
+	const query string = "SELECT user_relationships.user_id, users.username FROM user_relationships " +
+		"INNER JOIN users ON users.id = user_relationships.user_id " +
+		"WHERE user_relationships.target_user_id = ? AND (user_relationships.relationship & ?) != 0"
+
+	requests := make([]*FriendRequest, 0)
+
+	err := SQL.Select(&requests, query, userId, RelationshipPending)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// GetBlockedUsers Retrieves a slice of user ids that a given user has blocked
+func GetBlockedUsers(userId int) ([]int, error) {
+	const query string = "SELECT target_user_id FROM user_relationships WHERE user_id = ? AND (relationship & ?) != 0"
+
+	blocked := make([]int, 0)
+
+	err := SQL.Select(&blocked, query, userId, RelationshipBlocked)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blocked, nil
+}
+
 // GetUserRelationship Gets a relationship with a user
 func GetUserRelationship(userId int, targetUserId int) (*UserRelationship, error) {
 	const query string = "SELECT * FROM user_relationships WHERE user_id = ? AND target_user_id = ? LIMIT 1"
@@ -42,11 +121,54 @@ func GetUserRelationship(userId int, targetUserId int) (*UserRelationship, error
 	return &relationship, nil
 }
 
+// SetUserRelationship Sets the relationship a user has with another user, creating it if it doesn't exist
+func SetUserRelationship(userId int, targetUserId int, relationship int) error {
+	existing, err := GetUserRelationship(userId, targetUserId)
+
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		_, err = SQL.Exec("INSERT INTO user_relationships (user_id, target_user_id, relationship) VALUES (?, ?, ?)",
+			userId, targetUserId, relationship)
+	} else {
+		_, err = SQL.Exec("UPDATE user_relationships SET relationship = ? WHERE id = ?", relationship, existing.Id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SendFriendRequest Sends a friend request from a user to another user
+func SendFriendRequest(userId int, targetUserId int) error {
+	return SetUserRelationship(userId, targetUserId, RelationshipPending)
+}
+
+// AcceptFriendRequest Accepts a friend request that was sent to a user, making both users friends
+func AcceptFriendRequest(userId int, requesterId int) error {
+	err := SetUserRelationship(requesterId, userId, RelationshipFriend)
+
+	if err != nil {
+		return err
+	}
+
+	return SetUserRelationship(userId, requesterId, RelationshipFriend)
+}
+
+// DeclineFriendRequest Declines a friend request that was sent to a user
+func DeclineFriendRequest(userId int, requesterId int) error {
+	return SetUserRelationship(requesterId, userId, RelationshipDeclined)
+}
+
 // AddFriend Adds a player to a user's friends list
 func AddFriend(userId int, targetUserId int) error {
 	const query string = "INSERT INTO user_relationships (user_id, target_user_id, relationship) VALUES (?, ?, ?)"
 
-	_, err := SQL.Exec(query, userId, targetUserId, 1)
+	_, err := SQL.Exec(query, userId, targetUserId, RelationshipFriend)
 
 	if err != nil {
 		return err
@@ -67,3 +189,44 @@ func RemoveFriend(userId int, targetUserId int) error {
 
 	return nil
 }
+
+// RemoveFriendship Removes the friendship or pending friend requests between two users in both directions.
+// Blocks are kept. The user's own declined friend request is also kept, so they can't get around it by removing
+// the other user, but the other user removing them clears it.
+func RemoveFriendship(userId int, targetUserId int) error {
+	const query string = "DELETE FROM user_relationships WHERE " +
+		"(user_id = ? AND target_user_id = ? AND (relationship & ?) = 0) OR " +
+		"(user_id = ? AND target_user_id = ? AND (relationship & ?) = 0)"
+
+	_, err := SQL.Exec(query, userId, targetUserId, RelationshipBlocked|RelationshipDeclined, targetUserId, userId, RelationshipBlocked)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BlockUser Blocks a user, which also removes any friendship or friend requests between the two users
+func BlockUser(userId int, targetUserId int) error {
+	err := RemoveFriendship(userId, targetUserId)
+
+	if err != nil {
+		return err
+	}
+
+	return SetUserRelationship(userId, targetUserId, RelationshipBlocked)
+}
+
+// UnblockUser Unblocks a user
+func UnblockUser(userId int, targetUserId int) error {
+	const query string = "DELETE FROM user_relationships WHERE user_id = ? AND target_user_id = ? AND (relationship & ?) != 0"
+
+	_, err := SQL.Exec(query, userId, targetUserId, RelationshipBlocked)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -9,7 +9,7 @@ import (
 func TestUserFriendsList(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -31,7 +31,7 @@ func TestUserFriendsList(t *testing.T) {
 func TestGetUserRelationship(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -51,7 +51,7 @@ func TestGetUserRelationship(t *testing.T) {
 func TestAddFriend(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -72,3 +72,68 @@ func TestAddFriend(t *testing.T) {
 	CloseSQLConnection()
 }
 
+func TestRemoveFriendshipKeepsDeclinedRequests(t *testing.T) {
+	_ = config.Load("../config.json")
+
+	if config.Get() == nil {
+		return
+	}
+
+	InitializeSQL()
+
+	if err := SendFriendRequest(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeclineFriendRequest(2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveFriendship(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	relationship, err := GetUserRelationship(1, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !relationship.Has(RelationshipDeclined) {
+		t.Fatal("expected the declined friend request to be kept")
+	}
+
+	if err := RemoveFriendship(2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	relationship, err = GetUserRelationship(1, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if relationship != nil {
+		t.Fatal("expected the declined friend request to be cleared by the user who declined it")
+	}
+
+	if err := RemoveFriend(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	CloseSQLConnection()
+}
+
+func TestUserRelationshipHas(t *testing.T) {
+	var missing *UserRelationship
+
+	if missing.Has(RelationshipFriend) {
+		t.Fatal("expected a missing relationship to have no bits set")
+	}
+
+	relationship := &UserRelationship{Relationship: RelationshipPending}
+
+	if !relationship.Has(RelationshipFriend|RelationshipPending) || relationship.Has(RelationshipFriend) {
+		t.Fatalf("unexpected bits for relationship %v", relationship.Relationship)
+	}
+}
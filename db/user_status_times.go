@@ -0,0 +1,61 @@
+package db
+
+import (
+	"example.com/Quaver/Z/objects"
+	"strings"
+	"time"
+)
+
+// GetUserStatusTimes Retrieves how long a user has spent in each client status across all of their sessions
+func GetUserStatusTimes(userId int) (map[objects.ClientStatusType]time.Duration, error) {
+	var rows []struct {
+		Status  objects.ClientStatusType `db:"status"`
+		Seconds int64                    `db:"seconds"`
+	}
+
+	err := SQL.Select(&rows, "SELECT status, seconds FROM user_status_times WHERE user_id = ?", userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	times := make(map[objects.ClientStatusType]time.Duration, len(rows))
+
+	for _, row := range rows {
+		times[row.Status] = time.Duration(row.Seconds) * time.Second
+	}
+
+	return times, nil
+}
+
+// AddUserStatusTimes Adds the time a user spent in each client status during a session to their totals
+func AddUserStatusTimes(userId int, times map[objects.ClientStatusType]time.Duration) error {
+	var values []string
+	var args []interface{}
+
+	for status, duration := range times {
+		seconds := int64(duration.Seconds())
+
+		if seconds <= 0 {
+			continue
+		}
+
+		values = append(values, "(?, ?, ?)")
+		args = append(args, userId, status, seconds)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO user_status_times (user_id, status, seconds) VALUES " + strings.Join(values, ", ") +
+		" ON DUPLICATE KEY UPDATE seconds = seconds + VALUES(seconds)"
+
+	_, err := SQL.Exec(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
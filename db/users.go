@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/Philipp15b/go-steamapi"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,6 +19,10 @@ type User struct {
 	Privileges      common.Privileges `db:"privileges"`
 	UserGroups      common.UserGroups `db:"usergroups"`
 	MuteEndTime     int64             `db:"mute_endtime"`
+	BanEndTime      int64             `db:"ban_endtime"`
+	BanReason       sql.NullString    `db:"ban_reason"`
+	Presence        int               `db:"presence"`
+	LatestActivity  int64             `db:"latest_activity"`
 	Country         string            `db:"country"`
 	AvatarUrl       sql.NullString    `db:"avatar_url"`
 	TwitchUsername  sql.NullString    `db:"twitch_username"`
@@ -31,9 +36,59 @@ func (u *User) GetProfileUrl() string {
 	return fmt.Sprintf("https://quavergame.com/user/%v", u.Id)
 }
 
+// IsBanned Returns if the user is currently banned. Bans without an end time are permanent.
+func (u *User) IsBanned() bool {
+	return !u.Allowed && (u.BanEndTime == 0 || u.BanEndTime > time.Now().UnixMilli())
+}
+
+// IsBanExpired Returns if the user was temporarily banned and the ban has run out
+func (u *User) IsBanExpired() bool {
+	return !u.Allowed && u.BanEndTime != 0 && u.BanEndTime <= time.Now().UnixMilli()
+}
+
+// GetBanMessage Returns the message shown to the user when they're banned, with the reason and the time remaining
+func (u *User) GetBanMessage() string {
+	message := "You are permanently banned."
+
+	if u.BanEndTime != 0 {
+		message = fmt.Sprintf("You are banned for another %v.", formatBanDuration(time.Until(time.UnixMilli(u.BanEndTime))))
+	}
+
+	if u.BanReason.Valid && u.BanReason.String != "" {
+		message += fmt.Sprintf(" Reason: %v.", strings.TrimSuffix(u.BanReason.String, "."))
+	}
+
+	return message + " You can appeal your ban at: discord.gg/quaver"
+}
+
+// Formats the remaining time of a ban in days, hours and minutes (ex. 2d 5h 30m)
+func formatBanDuration(duration time.Duration) string {
+	minutes := int(duration.Round(time.Minute).Minutes())
+
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	var parts []string
+
+	if days := minutes / (60 * 24); days > 0 {
+		parts = append(parts, fmt.Sprintf("%vd", days))
+	}
+
+	if hours := minutes / 60 % 24; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%vh", hours))
+	}
+
+	if minutes%60 > 0 {
+		parts = append(parts, fmt.Sprintf("%vm", minutes%60))
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // GetUserById Retrieves a user from the database by their id
 func GetUserById(id int) (*User, error) {
-	query := "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, country, avatar_url, twitch_username, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE users.id = ? LIMIT 1"
+	query := "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, ban_endtime, ban_reason, presence, country, avatar_url, twitch_username, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE users.id = ? LIMIT 1"
 
 	var user User
 	err := SQL.Get(&user, query, id)
@@ -47,7 +102,7 @@ func GetUserById(id int) (*User, error) {
 
 // GetUserBySteamId Retrieves a user from the database by their Steam id
 func GetUserBySteamId(steamId string) (*User, error) {
-	query := "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, country, avatar_url, twitch_username, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE steam_id = ? LIMIT 1"
+	query := "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, ban_endtime, ban_reason, presence, country, avatar_url, twitch_username, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE steam_id = ? LIMIT 1"
 
 	var user User
 	err := SQL.Get(&user, query, steamId)
@@ -61,7 +116,7 @@ func GetUserBySteamId(steamId string) (*User, error) {
 
 // GetUserByUsername Rerieves a user from the database by their username
 func GetUserByUsername(username string) (*User, error) {
-	query := "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, country, avatar_url, twitch_username, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE username = ? LIMIT 1"
+	query := "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, ban_endtime, ban_reason, presence, country, avatar_url, twitch_username, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE username = ? LIMIT 1"
 
 	var user User
 	err := SQL.Get(&user, query, username)
@@ -73,6 +128,27 @@ func GetUserByUsername(username string) (*User, error) {
 	return &user, nil
 }
 
+// SearchUsersByUsername Retrieves the users whose username starts with a given query, excluding banned users
+func SearchUsersByUsername(query string, limit int) ([]*User, error) {
+	const sqlQuery string = "SELECT users.id, steam_id, username, allowed, privileges, usergroups, mute_endtime, country, avatar_url, latest_activity, clan_id, clans.tag AS clan_tag, clans.accent_color AS clan_accent_color " +
+		"FROM users LEFT JOIN clans ON users.clan_id = clans.id WHERE username LIKE ? AND allowed = 1 ORDER BY username LIMIT ?"
+
+	users := make([]*User, 0)
+
+	err := SQL.Select(&users, sqlQuery, escapeLike(query)+"%", limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Escapes the wildcards in a string, so it can be matched literally with LIKE
+func escapeLike(str string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(str)
+}
+
 // UpdateUserLatestActivity Updates the latest_activity of a user to the current time
 func UpdateUserLatestActivity(id int) error {
 	_, err := SQL.Exec("UPDATE users SET latest_activity = ? WHERE id = ?", time.Now().UnixMilli(), id)
@@ -93,7 +169,7 @@ func UpdateUserSteamAvatar(steamId string) (string, error) {
 		panic(err)
 	}
 
-	summaries, err := steamapi.GetPlayerSummaries([]uint64{uint64(parsedId)}, config.Instance.Steam.APIKey)
+	summaries, err := steamapi.GetPlayerSummaries([]uint64{uint64(parsedId)}, config.Get().Steam.APIKey)
 
 	if err != nil {
 		return "", err
@@ -125,6 +201,51 @@ func MuteUser(id int, endTime int64) error {
 	return nil
 }
 
+// BanUser Bans a user until the given end time. An end time of zero bans the user permanently.
+func BanUser(id int, endTime int64, reason string) error {
+	_, err := SQL.Exec("UPDATE users SET allowed = 0, ban_endtime = ?, ban_reason = ? WHERE id = ?", endTime, reason, id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnbanUser Lifts a user's ban
+func UnbanUser(id int) error {
+	_, err := SQL.Exec("UPDATE users SET allowed = 1, ban_endtime = 0, ban_reason = NULL WHERE id = ?", id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LiftExpiredBans Lifts every temporary ban that has run out. Returns the amount of users that were unbanned.
+func LiftExpiredBans() (int64, error) {
+	result, err := SQL.Exec("UPDATE users SET allowed = 1, ban_endtime = 0, ban_reason = NULL WHERE allowed = 0 AND ban_endtime != 0 AND ban_endtime <= ?",
+		time.Now().UnixMilli())
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// UpdateUserPresence Updates who is able to see that a user is online
+func UpdateUserPresence(id int, presence int) error {
+	_, err := SQL.Exec("UPDATE users SET presence = ? WHERE id = ?", presence, id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // UnlinkUserTwitch Unlinks the twitch account of a given user
 func UnlinkUserTwitch(id int) error {
 	_, err := SQL.Exec("UPDATE users SET twitch_username = NULL WHERE id = ?", id)
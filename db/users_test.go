@@ -1,14 +1,16 @@
 package db
 
 import (
+	"database/sql"
 	"example.com/Quaver/Z/config"
 	"testing"
+	"time"
 )
 
 func TestGetUserBySteamId(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -26,7 +28,7 @@ func TestGetUserBySteamId(t *testing.T) {
 func TestUpdateUserLatestActivity(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -44,7 +46,7 @@ func TestUpdateUserLatestActivity(t *testing.T) {
 func TestUpdateUserSteamAvatar(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -58,3 +60,32 @@ func TestUpdateUserSteamAvatar(t *testing.T) {
 
 	CloseSQLConnection()
 }
+
+func TestGetBanMessage(t *testing.T) {
+	user := &User{Allowed: false}
+
+	if !user.IsBanned() || user.IsBanExpired() {
+		t.Fatal("expected a ban without an end time to be permanent")
+	}
+
+	user.BanEndTime = time.Now().Add(-time.Minute).UnixMilli()
+
+	if user.IsBanned() || !user.IsBanExpired() {
+		t.Fatal("expected the ban to have expired")
+	}
+
+	user.BanEndTime = time.Now().Add(26*time.Hour + 30*time.Minute).UnixMilli()
+	user.BanReason = sql.NullString{String: "Cheating", Valid: true}
+
+	expected := "You are banned for another 1d 2h 30m. Reason: Cheating. You can appeal your ban at: discord.gg/quaver"
+
+	if message := user.GetBanMessage(); message != expected {
+		t.Fatalf("expected %q, got %q", expected, message)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if escaped := escapeLike(`100%_sure\`); escaped != `100\%\_sure\\` {
+		t.Fatalf("expected wildcards to be escaped, got %q", escaped)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"log"
+)
+
+// Handles when the client requests to block/unblock a user
+func handleClientBlockUser(user *sessions.User, packet *packets.ClientBlockUser) {
+	if packet.UserId == user.Info.Id {
+		return
+	}
+
+	switch packet.Action {
+	case packets.BlockListActionBlock:
+		if user.HasBlocked(packet.UserId) {
+			return
+		}
+
+		target, err := db.GetUserById(packet.UserId)
+
+		if err != nil || common.HasUserGroup(target.UserGroups, common.UserGroupBot) {
+			return
+		}
+
+		err = db.BlockUser(user.Info.Id, packet.UserId)
+
+		if err != nil {
+			log.Printf("Failed to block user (#%v -> #%v) - %v\n", user.Info.Id, packet.UserId, err)
+			return
+		}
+
+		user.SetUserBlocked(packet.UserId, true)
+		stopSpectatingBlockedUser(user, packet.UserId)
+
+		// Blocking removes the friendship between the users
+		sendFriendsListToUsers(user.Info.Id, packet.UserId)
+	case packets.BlockListActionUnblock:
+		if !user.HasBlocked(packet.UserId) {
+			return
+		}
+
+		err := db.UnblockUser(user.Info.Id, packet.UserId)
+
+		if err != nil {
+			log.Printf("Failed to unblock user (#%v -> #%v) - %v\n", user.Info.Id, packet.UserId, err)
+			return
+		}
+
+		user.SetUserBlocked(packet.UserId, false)
+	default:
+		return
+	}
+
+	sessions.SendPacketToUser(packets.NewServerBlockedUsersList(user.GetBlockedUsers()), user)
+}
+
+// Stops either user from spectating the other after a block
+func stopSpectatingBlockedUser(user *sessions.User, blockedUserId int) {
+	blockedUser := sessions.GetUserById(blockedUserId)
+
+	if blockedUser == nil {
+		return
+	}
+
+	if utils.Includes(user.GetSpectators(), blockedUser) {
+		user.RemoveSpectator(blockedUser)
+	}
+
+	if utils.Includes(blockedUser.GetSpectators(), user) {
+		blockedUser.RemoveSpectator(user)
+	}
+}
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client is requesting to change the map for their MP game.
-func handleClientChangeGameMap(user *sessions.User, packet *packets.ClientChangeGameMap) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.ChangeMap(user, packet)
-	})
+func handleClientChangeGameMap(user *sessions.User, game *multiplayer.Game, packet *packets.ClientChangeGameMap) {
+	game.ChangeMap(user, packet)
 }
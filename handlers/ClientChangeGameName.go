@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to change the name of the game
-func handleClientChangeGameName(user *sessions.User, packet *packets.ClientChangeGameName) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.ChangeName(user, packet.Name)
-	})
+func handleClientChangeGameName(user *sessions.User, game *multiplayer.Game, packet *packets.ClientChangeGameName) {
+	game.ChangeName(user, packet.Name)
 }
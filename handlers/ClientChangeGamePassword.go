@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to change the password of a multiplayer game
-func handleClientChangeGamePassword(user *sessions.User, packet *packets.ClientChangeGamePassword) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPassword(user, packet.Password)
-	})
+func handleClientChangeGamePassword(user *sessions.User, game *multiplayer.Game, packet *packets.ClientChangeGamePassword) {
+	game.SetPassword(user, packet.Password)
 }
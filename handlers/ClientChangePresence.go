@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"log"
+)
+
+// Handles when the client changes who is able to see that they're online
+func handleClientChangePresence(user *sessions.User, packet *packets.ClientChangePresence) {
+	if !packet.Presence.IsValid() || packet.Presence == user.GetPresence() {
+		return
+	}
+
+	err := db.UpdateUserPresence(user.Info.Id, int(packet.Presence))
+
+	if err != nil {
+		log.Printf("Failed to update presence of #%v - %v\n", user.Info.Id, err)
+		return
+	}
+
+	updatePresence(user, func() {
+		user.SetPresence(packet.Presence)
+	})
+
+	sessions.SendPacketToUser(packets.NewServerPresence(packet.Presence), user)
+}
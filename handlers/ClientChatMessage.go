@@ -8,9 +8,5 @@ import (
 
 // Handles when a user sends a chat message
 func handleClientChatMessage(user *sessions.User, packet *packets.ClientChatMessage) {
-	if packet == nil {
-		return
-	}
-
 	chat.SendMessage(user, packet.Receiver, packet.Message)
 }
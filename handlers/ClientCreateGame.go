@@ -9,7 +9,7 @@ import (
 
 // Handles when the user wants to create a multiplayer game.
 func handleClientCreateGame(user *sessions.User, packet *packets.ClientCreateGame) {
-	if packet == nil || packet.Game == nil {
+	if packet.Game == nil {
 		return
 	}
 
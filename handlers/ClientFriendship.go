@@ -7,12 +7,8 @@ import (
 	"log"
 )
 
-// Handles when the client requests to add/remove a user
+// Handles when the client requests to add/remove a user, or answers a friend request
 func handleClientFriendship(user *sessions.User, packet *packets.ClientFriendship) {
-	if packet == nil {
-		return
-	}
-
 	if packet.UserId == user.Info.Id {
 		return
 	}
@@ -21,28 +17,142 @@ func handleClientFriendship(user *sessions.User, packet *packets.ClientFriendshi
 
 	if err != nil {
 		log.Printf("Failed to get user relationship (#%v -> #%v) - %v\n", user.Info.Id, packet.UserId, err)
+		return
+	}
+
+	targetRelationship, err := db.GetUserRelationship(packet.UserId, user.Info.Id)
+
+	if err != nil {
+		log.Printf("Failed to get user relationship (#%v -> #%v) - %v\n", packet.UserId, user.Info.Id, err)
+		return
 	}
 
+	// Neither user can send friend requests to, or accept them from, someone they've blocked or been blocked by
+	blocked := relationship.Has(db.RelationshipBlocked) || targetRelationship.Has(db.RelationshipBlocked)
+
 	switch packet.Action {
 	case packets.FriendsListActionAdd:
-		if relationship != nil {
+		if blocked {
+			return
+		}
+
+		// Users whose request was declined can't keep sending new ones
+		if relationship.Has(db.RelationshipFriend | db.RelationshipPending | db.RelationshipDeclined) {
+			return
+		}
+
+		// Adding someone who already sent a request is the same as accepting it
+		if targetRelationship.Has(db.RelationshipPending) {
+			acceptFriendRequest(user, packet.UserId)
 			return
 		}
 
-		err = db.AddFriend(user.Info.Id, packet.UserId)
+		sendFriendRequest(user, packet.UserId)
+	case packets.FriendsListActionAcceptRequest:
+		if blocked || !targetRelationship.Has(db.RelationshipPending) {
+			return
+		}
+
+		acceptFriendRequest(user, packet.UserId)
+	case packets.FriendsListActionDeclineRequest:
+		if !targetRelationship.Has(db.RelationshipPending) {
+			return
+		}
+
+		err = db.DeclineFriendRequest(user.Info.Id, packet.UserId)
 
 		if err != nil {
-			log.Printf("Failed to add friend (#%v -> #%v) - %v\n", user.Info.Id, packet.UserId, err)
+			log.Printf("Failed to decline friend request (#%v -> #%v) - %v\n", packet.UserId, user.Info.Id, err)
 		}
 	case packets.FriendsListActionRemove:
-		if relationship == nil {
+		if relationship == nil && targetRelationship == nil {
 			return
 		}
 
-		err = db.RemoveFriend(user.Info.Id, packet.UserId)
+		err = db.RemoveFriendship(user.Info.Id, packet.UserId)
 
 		if err != nil {
 			log.Printf("Failed to remove friend (#%v -> #%v) - #%v\n", user.Info.Id, packet.UserId, err)
+			return
+		}
+
+		sendFriendsListToUsers(user.Info.Id, packet.UserId)
+	}
+}
+
+// Sends a friend request and lets the target know if they're online. Offline users receive it when they next log in.
+func sendFriendRequest(user *sessions.User, targetUserId int) {
+	if _, err := db.GetUserById(targetUserId); err != nil {
+		return
+	}
+
+	err := db.SendFriendRequest(user.Info.Id, targetUserId)
+
+	if err != nil {
+		log.Printf("Failed to send friend request (#%v -> #%v) - %v\n", user.Info.Id, targetUserId, err)
+		return
+	}
+
+	if target := sessions.GetUserById(targetUserId); target != nil {
+		sessions.SendPacketToUser(packets.NewServerFriendRequest(user.Info.Id, user.Info.Username), target)
+	}
+}
+
+// Accepts a friend request and sends both users their updated friends list
+func acceptFriendRequest(user *sessions.User, requesterId int) {
+	err := db.AcceptFriendRequest(user.Info.Id, requesterId)
+
+	if err != nil {
+		log.Printf("Failed to accept friend request (#%v -> #%v) - %v\n", requesterId, user.Info.Id, err)
+		return
+	}
+
+	sendFriendsListToUsers(user.Info.Id, requesterId)
+}
+
+// Sends the up-to-date friends list to each of the given users that are online
+func sendFriendsListToUsers(userIds ...int) {
+	for _, userId := range userIds {
+		user := sessions.GetUserById(userId)
+
+		if user == nil {
+			continue
+		}
+
+		if err := sendFriendsList(user); err != nil {
+			log.Printf("Failed to send friends list to #%v - %v\n", userId, err)
 		}
 	}
 }
+
+// Sends a user their friends list. Friends can see users who appear offline, so the users who gained or lost
+// a friend are told that the user has come online or gone offline.
+func sendFriendsList(user *sessions.User) error {
+	friends, err := db.GetUserFriendsList(user.Info.Id)
+
+	if err != nil {
+		return err
+	}
+
+	updatePresence(user, func() {
+		user.SetFriends(friends)
+	})
+
+	sessions.SendPacketToUser(packets.NewServerFriendsList(friends), user)
+	return nil
+}
+
+// Sends a user the friend requests they received while they were offline
+func sendPendingFriendRequests(user *sessions.User) error {
+	requests, err := db.GetPendingFriendRequests(user.Info.Id)
+
+	if err != nil {
+		return err
+	}
+
+	for _, request := range requests {
+		sessions.SendPacketToUser(packets.NewServerFriendRequest(request.UserId, request.Username), user)
+	}
+
+	return nil
+}
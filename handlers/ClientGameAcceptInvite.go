@@ -8,10 +8,6 @@ import (
 
 // Handles when the client accepts a game invite
 func handleClientGameAcceptInvite(user *sessions.User, packet *packets.ClientGameAcceptInvite) {
-	if packet == nil {
-		return
-	}
-
 	game := multiplayer.GetGameByIdString(packet.MatchId)
 
 	if game == nil {
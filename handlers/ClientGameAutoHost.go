@@ -7,16 +7,6 @@ import (
 )
 
 // Handles when the client requests to turn on autohost
-func handleClientGameAutoHost(user *sessions.User, packet *packets.ClientGameAutoHost) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
+func handleClientGameAutoHost(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameAutoHost) {
 	game.SetAutoHost(user, packet.Enabled)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client wants to change the free mod type in a multiplayer game
-func handleClientGameChangeFreeMod(user *sessions.User, packet *packets.ClientGameFreeModTypeChanged) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetFreeMod(user, packet.Type)
-	})
+func handleClientGameChangeFreeMod(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameFreeModTypeChanged) {
+	game.SetFreeMod(user, packet.Type)
 }
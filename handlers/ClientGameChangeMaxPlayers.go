@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to change the max player count of a multiplayer game
-func handleClientGameChangeMaxPlayers(user *sessions.User, packet *packets.ClientGameChangeMaxPlayers) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetMaxPlayerCount(user, packet.Count)
-	})
+func handleClientGameChangeMaxPlayers(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameChangeMaxPlayers) {
+	game.SetMaxPlayerCount(user, packet.Count)
 }
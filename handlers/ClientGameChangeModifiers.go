@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client wants to change the modifiers of a multiplayer game
-func handleClientGameChangeModifiers(user *sessions.User, packet *packets.ClientGameChangeModifiers) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetGlobalModifiers(user, packet.Modifiers, packet.DifficultyRating)
-	})
+func handleClientGameChangeModifiers(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameChangeModifiers) {
+	game.SetGlobalModifiers(user, packet.Modifiers, packet.DifficultyRating)
 }
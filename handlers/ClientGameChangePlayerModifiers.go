@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to change their modifiers in multiplayer
-func handleClientGameChangePlayerModifiers(user *sessions.User, packet *packets.ClientGameChangePlayerModifiers) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerModifiers(user.Info.Id, packet.Modifiers)
-	})
+func handleClientGameChangePlayerModifiers(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameChangePlayerModifiers) {
+	game.SetPlayerModifiers(user.Info.Id, packet.Modifiers)
 }
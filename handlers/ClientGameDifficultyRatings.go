@@ -7,16 +7,6 @@ import (
 )
 
 // Handles when the client sends us difficulty ratings to use
-func handleClientGameDifficultyRatings(user *sessions.User, packet *packets.ClientGameDifficultyRatings) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
+func handleClientGameDifficultyRatings(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameDifficultyRatings) {
 	game.SetClientProvidedDifficultyRatings(packet.Md5, packet.AlternativeMd5, packet.Difficulties)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client wants to enable/disable preview for their multiplayer game
-func handleClientGameEnablePreview(user *sessions.User, packet *packets.ClientGameEnablePreview) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetEnablePreview(user, packet.Enabled)
-	})
+func handleClientGameEnablePreview(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameEnablePreview) {
+	game.SetEnablePreview(user, packet.Enabled)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client wants to enable/disable host rotation for their multiplayer game
-func handleClientGameHostRotation(user *sessions.User, packet *packets.ClientGameHostRotation) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetHostRotation(user, packet.Enabled)
-	})
+func handleClientGameHostRotation(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameHostRotation) {
+	game.SetHostRotation(user, packet.Enabled)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client is stating that they are/aren't selecting a map
-func handleClientGameHostSelectingMap(user *sessions.User, packet *packets.ClientGameHostSelectingMap) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetHostSelectingMap(user, packet.IsSelecting, true)
-	})
+func handleClientGameHostSelectingMap(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameHostSelectingMap) {
+	game.SetHostSelectingMap(user, packet.IsSelecting, true)
 }
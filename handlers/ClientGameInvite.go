@@ -7,17 +7,7 @@ import (
 )
 
 // Handles when the client wishes to invite someone to their game
-func handleClientGameInvite(user *sessions.User, packet *packets.ClientGameInvite) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
+func handleClientGameInvite(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameInvite) {
 	invitee := sessions.GetUserById(packet.UserId)
 
 	if invitee == nil {
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client sends judgements in the multiplayer match
-func handleClientGameJudgements(user *sessions.User, packet *packets.ClientGameJudgements) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.HandlePlayerJudgements(user.Info.Id, packet.Judgements, packet.MineHitDelta)
-	})
+func handleClientGameJudgements(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameJudgements) {
+	game.HandlePlayerJudgements(user.Info.Id, packet.Judgements, packet.MineHitDelta)
 }
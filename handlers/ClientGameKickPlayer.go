@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client is requesting to kick someone from a multiplayer game
-func handleClientGameKickPlayer(user *sessions.User, packet *packets.ClientGameKickPlayer) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.KickPlayer(user, packet.UserId)
-	})
+func handleClientGameKickPlayer(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameKickPlayer) {
+	game.KickPlayer(user, packet.UserId)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client states that they've finished in multiplayer
-func handleClientGamePlayerFinished(user *sessions.User, packet *packets.ClientGamePlayerFinished) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerFinished(user.Info.Id)
-	})
+func handleClientGamePlayerFinished(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGamePlayerFinished) {
+	game.SetPlayerFinished(user.Info.Id)
 }
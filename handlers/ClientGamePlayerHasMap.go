@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client states that they now have the multiplayer map
-func handleClientGamePlayerHasMap(user *sessions.User, packet *packets.ClientGamePlayerHasMap) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerHasMap(user.Info.Id)
-	})
+func handleClientGamePlayerHasMap(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGamePlayerHasMap) {
+	game.SetPlayerHasMap(user.Info.Id)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client states that they don't have the current map in multiplayer
-func handleClientGamePlayerNoMap(user *sessions.User, packet *packets.ClientGamePlayerNoMap) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerDoesntHaveMap(user.Info.Id)
-	})
+func handleClientGamePlayerNoMap(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGamePlayerNoMap) {
+	game.SetPlayerDoesntHaveMap(user.Info.Id)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when a player states that they are no longer ready in the multiplayer game
-func handleClientGamePlayerNotReady(user *sessions.User, packet *packets.ClientGamePlayerNotReady) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerNotReady(user.Info.Id)
-	})
+func handleClientGamePlayerNotReady(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGamePlayerNotReady) {
+	game.SetPlayerNotReady(user.Info.Id)
 }
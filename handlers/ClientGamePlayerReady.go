@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when a player states that they are not ready
-func handleClientGamePlayerReady(user *sessions.User, packet *packets.ClientGamePlayerReady) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerReady(user.Info.Id)
-	})
+func handleClientGamePlayerReady(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGamePlayerReady) {
+	game.SetPlayerReady(user.Info.Id)
 }
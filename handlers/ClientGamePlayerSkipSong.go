@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to skip the song during multiplayer
-func handleClientGamePlayerSkipSong(user *sessions.User, packet *packets.ClientGamePlayerSkipSong) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerSkippedSong(user.Info.Id)
-	})
+func handleClientGamePlayerSkipSong(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGamePlayerSkipSong) {
+	game.SetPlayerSkippedSong(user.Info.Id)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client states that their screen has loaded in-game
-func handleClientGameScreenLoaded(user *sessions.User, packet *packets.ClientGameScreenLoaded) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetPlayerScreenLoaded(user.Info.Id)
-	})
+func handleClientGameScreenLoaded(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameScreenLoaded) {
+	game.SetPlayerScreenLoaded(user.Info.Id)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to start the game countdown
-func handleClientGameStartCountdown(user *sessions.User, packet *packets.ClientGameStartCountdown) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.StartCountdown(user)
-	})
+func handleClientGameStartCountdown(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameStartCountdown) {
+	game.StartCountdown(user)
 }
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to stop the game countdown
-func handleClientGameStopCountdown(user *sessions.User, packet *packets.ClientGameStopCountdown) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.StopCountdown(user)
-	})
+func handleClientGameStopCountdown(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameStopCountdown) {
+	game.StopCountdown(user)
 }
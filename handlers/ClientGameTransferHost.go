@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client wishes to transfer host to another player
-func handleClientGameTransferHost(user *sessions.User, packet *packets.ClientGameTransferHost) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.SetHost(user, packet.UserId)
-	})
+func handleClientGameTransferHost(user *sessions.User, game *multiplayer.Game, packet *packets.ClientGameTransferHost) {
+	game.SetHost(user, packet.UserId)
 }
@@ -8,10 +8,6 @@ import (
 
 // Handles when a user attempts to join a game
 func handleClientJoinGame(user *sessions.User, packet *packets.ClientJoinGame) {
-	if packet == nil {
-		return
-	}
-
 	game := multiplayer.GetGameByIdString(packet.GameId)
 
 	if game == nil {
@@ -7,18 +7,6 @@ import (
 )
 
 // Handles when the client requests to leave a game
-func handleClientLeaveGame(user *sessions.User, packet *packets.ClientLeaveGame) {
-	if packet == nil {
-		return
-	}
-
-	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
-
-	if game == nil {
-		return
-	}
-
-	game.RunLocked(func() {
-		game.RemovePlayer(user.Info.Id)
-	})
+func handleClientLeaveGame(user *sessions.User, game *multiplayer.Game, packet *packets.ClientLeaveGame) {
+	game.RemovePlayer(user.Info.Id)
 }
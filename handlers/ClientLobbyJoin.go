@@ -8,9 +8,5 @@ import (
 
 // Handles when a client requests to join the multiplayer lobby
 func handleClientLobbyJoin(user *sessions.User, packet *packets.ClientLobbyJoin) {
-	if packet == nil {
-		return
-	}
-
 	multiplayer.AddUserToLobby(user)
 }
@@ -8,9 +8,5 @@ import (
 
 // Handles when the client requests to leave the multiplayer lobby
 func handleClientLobbyLeave(user *sessions.User, packet *packets.ClientLobbyLeave) {
-	if packet == nil {
-		return
-	}
-
 	multiplayer.RemoveUserFromLobby(user)
 }
@@ -7,10 +7,6 @@ import (
 
 // Called when the client requests to log out.
 func handleClientLogout(user *sessions.User, packet *packets.ClientLogout) {
-	if packet == nil {
-		return
-	}
-
 	err := HandleLogout(user.Conn)
 
 	if err != nil {
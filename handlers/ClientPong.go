@@ -11,10 +11,6 @@ import (
 
 // Handles when a user sends a pong packet
 func handleClientPong(user *sessions.User, packet *packets.ClientPong) {
-	if packet == nil {
-		return
-	}
-
 	user.SetLastPongTimestamp()
 
 	parsed := packet.Parse()
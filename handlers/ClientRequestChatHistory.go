@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests to load older messages in a chat channel
+func handleClientRequestChatHistory(user *sessions.User, packet *packets.ClientRequestChatHistory) {
+	if packet.Before < 0 {
+		return
+	}
+
+	chat.SendChatHistory(user, packet.Channel, packet.Before)
+}
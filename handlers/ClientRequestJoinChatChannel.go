@@ -8,10 +8,6 @@ import (
 
 // Handles when the user wants to join a chat channel
 func handleClientRequestJoinChatChannel(user *sessions.User, packet *packets.ClientRequestJoinChatChannel) {
-	if packet == nil {
-		return
-	}
-
 	channel := chat.GetChannelByName(packet.Channel)
 
 	if channel == nil {
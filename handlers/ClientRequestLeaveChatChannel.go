@@ -8,10 +8,6 @@ import (
 
 // Handles when a client requests to leave a given chat channel
 func handleClientRequestLeaveChatChannel(user *sessions.User, packet *packets.ClientRequestLeaveChatChannel) {
-	if packet == nil {
-		return
-	}
-
 	channel := chat.GetChannelByName(packet.Channel)
 
 	if channel == nil {
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"log"
+)
+
+// The amount of recent players that are sent to the client
+const recentPlayersLimit = 25
+
+// Handles when the client requests the users they've recently played with
+func handleClientRequestRecentPlayers(user *sessions.User, packet *packets.ClientRequestRecentPlayers) {
+	recent, err := db.GetRecentPlayers(user.Info.Id, recentPlayersLimit)
+
+	if err != nil {
+		log.Printf("Failed to retrieve recent players of #%v - %v\n", user.Info.Id, err)
+		return
+	}
+
+	players := make([]*objects.RecentPlayer, 0, len(recent))
+
+	for _, player := range recent {
+		players = append(players, &objects.RecentPlayer{
+			UserId:    player.UserId,
+			Username:  player.Username,
+			Type:      int(player.Type),
+			GameName:  player.GameName.String,
+			Timestamp: player.Timestamp,
+		})
+	}
+
+	sessions.SendPacketToUser(packets.NewServerRecentPlayers(players), user)
+}
@@ -8,22 +8,18 @@ import (
 
 // Handles when a client is requesting updated user info
 func handleClientRequestUserInfo(user *sessions.User, packet *packets.ClientRequestUserInfo) {
-	if packet == nil {
-		return
-	}
-
-	userInfo := getPacketUsersFromUserIds(packet.UserIds)
+	userInfo := getPacketUsersFromUserIds(user, packet.UserIds)
 	sessions.SendPacketToUser(packets.NewServerUserInfo(userInfo), user)
 }
 
-// Converts a slice of user ids into their respective packet users.
-func getPacketUsersFromUserIds(userIds []int) []*objects.PacketUser {
+// Converts a slice of user ids into their respective packet users. Users that appear offline to the viewer are left out.
+func getPacketUsersFromUserIds(viewer *sessions.User, userIds []int) []*objects.PacketUser {
 	var userInfo []*objects.PacketUser
 
 	for _, id := range userIds {
 		user := sessions.GetUserById(id)
 
-		if user == nil {
+		if user == nil || !sessions.CanSeeOnline(viewer, user) {
 			continue
 		}
 
@@ -3,22 +3,25 @@ package handlers
 import (
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 )
 
-// Handles when a client requests to retrieve a collection of users' stats
+// Handles when a client requests to retrieve a collection of users' stats. Only online users that the client can see are included.
 func handleClientRequestUserStats(user *sessions.User, packet *packets.ClientRequestUserStats) {
-	if packet == nil || packet.Users == nil || len(packet.Users) == 0 {
+	if packet.Users == nil || len(packet.Users) == 0 {
 		return
 	}
 
 	var statsObj = map[int]map[common.Mode]*db.PacketUserStats{}
+	var playtime = map[int]map[objects.ClientStatusType]int64{}
 
 	for _, packetUser := range packet.Users {
 		u := sessions.GetUserById(packetUser)
 
-		if u == nil {
+		// Live stats would give away that users who appear offline are online
+		if u == nil || !sessions.CanSeeOnline(user, u) {
 			continue
 		}
 
@@ -29,7 +32,13 @@ func handleClientRequestUserStats(user *sessions.User, packet *packets.ClientReq
 		for i, stat := range stats {
 			statsObj[packetUser][i] = stat.SerializeForPacket()
 		}
+
+		playtime[packetUser] = map[objects.ClientStatusType]int64{}
+
+		for status, duration := range u.GetTotalStatusTimes() {
+			playtime[packetUser][status] = int64(duration.Seconds())
+		}
 	}
 
-	sessions.SendPacketToUser(packets.NewServerUserStats(statsObj), user)
+	sessions.SendPacketToUser(packets.NewServerUserStats(statsObj, playtime), user)
 }
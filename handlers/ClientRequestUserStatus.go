@@ -8,21 +8,22 @@ import (
 
 // Handles when the client is requesting user statuses
 func handleClientRequestUserStatus(user *sessions.User, packet *packets.ClientRequestUserStatus) {
-	if packet == nil {
-		return
-	}
+	sessions.SendPacketToUser(packets.NewServerUserStatus(getClientStatuses(user, packet.UserIds)), user)
+}
 
+// Returns the statuses of the online users in a list that the viewer is able to see
+func getClientStatuses(viewer *sessions.User, userIds []int) packets.ClientStatus {
 	var statuses packets.ClientStatus = map[int]*objects.ClientStatus{}
 
-	for _, userId := range packet.UserIds {
+	for _, userId := range userIds {
 		user := sessions.GetUserById(userId)
 
-		if user == nil {
+		if user == nil || !sessions.CanSeeStatus(viewer, user) {
 			continue
 		}
 
 		statuses[userId] = user.GetClientStatus()
 	}
 
-	sessions.SendPacketToUser(packets.NewServerUserStatus(statuses), user)
+	return statuses
 }
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	minUserSearchQueryLength = 2
+	maxUserSearchQueryLength = 32
+	userSearchResultLimit    = 25
+)
+
+// Handles when the client searches for users by their username.
+// Online users are matched loosely, and offline users are found by the start of their username.
+func handleClientSearchUsers(user *sessions.User, packet *packets.ClientSearchUsers) {
+	query := strings.TrimSpace(packet.Query)
+
+	if len(query) < minUserSearchQueryLength || len(query) > maxUserSearchQueryLength {
+		return
+	}
+
+	results := searchOnlineUsers(user, query)
+
+	if len(results) < userSearchResultLimit {
+		users, err := db.SearchUsersByUsername(query, userSearchResultLimit)
+
+		if err != nil {
+			log.Printf("Failed to search users for `%v` - %v\n", query, err)
+			return
+		}
+
+		for _, found := range users {
+			if len(results) >= userSearchResultLimit {
+				break
+			}
+
+			if includesSearchedUser(results, found.Id) {
+				continue
+			}
+
+			// Users who are online but appear offline to the searcher are shown as offline
+			results = append(results, &objects.SearchedUser{
+				User:     sessions.SerializeUserForPacket(found),
+				Online:   false,
+				LastSeen: getSearchedUserLastSeen(user, found),
+			})
+		}
+	}
+
+	sessions.SendPacketToUser(packets.NewServerSearchUsersResults(packet.Query, results), user)
+}
+
+// Returns when a user found in the database was last seen, or 0 if the searcher isn't allowed to know.
+// The latest activity of users who appear offline would give away when they were last online.
+func getSearchedUserLastSeen(searcher *sessions.User, found *db.User) int64 {
+	if online := sessions.GetUserById(found.Id); online != nil {
+		if !sessions.CanSeeOnline(searcher, online) {
+			return 0
+		}
+
+		return found.LatestActivity
+	}
+
+	if objects.Presence(found.Presence) == objects.PresenceAppearOffline && !searcher.IsFriendsWith(found.Id) {
+		return 0
+	}
+
+	return found.LatestActivity
+}
+
+// Returns the online users that the searcher can see whose username matches a query, with the closest matches first
+func searchOnlineUsers(searcher *sessions.User, query string) []*objects.SearchedUser {
+	type match struct {
+		user  *sessions.User
+		score int
+	}
+
+	var matches []match
+
+	for _, user := range sessions.GetOnlineUsers() {
+		if !sessions.CanSeeOnline(searcher, user) {
+			continue
+		}
+
+		score := getUsernameMatchScore(user.Info.Username, query)
+
+		if score < 0 {
+			continue
+		}
+
+		matches = append(matches, match{user: user, score: score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+
+		return strings.ToLower(matches[i].user.Info.Username) < strings.ToLower(matches[j].user.Info.Username)
+	})
+
+	results := make([]*objects.SearchedUser, 0, userSearchResultLimit)
+
+	for _, match := range matches {
+		if len(results) >= userSearchResultLimit {
+			break
+		}
+
+		results = append(results, &objects.SearchedUser{User: match.user.SerializeForPacket(), Online: true})
+	}
+
+	return results
+}
+
+// Returns how closely a username matches a search query, or -1 if it doesn't match at all.
+// Exact matches rank the highest, followed by prefixes, substrings and usernames that contain the query's characters in order.
+func getUsernameMatchScore(username string, query string) int {
+	username = strings.ToLower(username)
+	query = strings.ToLower(query)
+
+	switch {
+	case username == query:
+		return 3
+	case strings.HasPrefix(username, query):
+		return 2
+	case strings.Contains(username, query):
+		return 1
+	case isSubsequence(query, username):
+		return 0
+	default:
+		return -1
+	}
+}
+
+// Returns if all the characters of a string appear in another string in the same order
+func isSubsequence(sub string, str string) bool {
+	remaining := []rune(sub)
+
+	for _, char := range str {
+		if len(remaining) == 0 {
+			break
+		}
+
+		if char == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+
+	return len(remaining) == 0
+}
+
+// Returns if a user is already in a list of search results
+func includesSearchedUser(results []*objects.SearchedUser, userId int) bool {
+	for _, result := range results {
+		if result.User.Id == userId {
+			return true
+		}
+	}
+
+	return false
+}
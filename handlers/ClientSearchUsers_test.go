@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/sessions"
+	"testing"
+)
+
+func TestGetUsernameMatchScore(t *testing.T) {
+	tests := []struct {
+		username string
+		query    string
+		expected int
+	}{
+		{"Swan", "swan", 3},
+		{"Swanky", "swa", 2},
+		{"BlackSwan", "swan", 1},
+		{"Swordsman", "swmn", 0},
+		{"Swan", "nawS", -1},
+	}
+
+	for _, test := range tests {
+		if score := getUsernameMatchScore(test.username, test.query); score != test.expected {
+			t.Fatalf("expected %v to score %v for %q, got %v", test.username, test.expected, test.query, score)
+		}
+	}
+}
+
+func TestGetSearchedUserLastSeen(t *testing.T) {
+	searcher := sessions.NewUser(nil, &db.User{Id: 1, Username: "User #1"})
+
+	visible := &db.User{Id: 2, LatestActivity: 1000, Presence: int(objects.PresenceEveryone)}
+	hidden := &db.User{Id: 3, LatestActivity: 1000, Presence: int(objects.PresenceAppearOffline)}
+
+	if lastSeen := getSearchedUserLastSeen(searcher, visible); lastSeen != 1000 {
+		t.Fatalf("expected the last seen time of a visible user, got %v", lastSeen)
+	}
+
+	if lastSeen := getSearchedUserLastSeen(searcher, hidden); lastSeen != 0 {
+		t.Fatalf("expected no last seen time for a user who appears offline, got %v", lastSeen)
+	}
+
+	searcher.SetFriends([]int{3})
+
+	if lastSeen := getSearchedUserLastSeen(searcher, hidden); lastSeen != 1000 {
+		t.Fatalf("expected friends to see the last seen time, got %v", lastSeen)
+	}
+}
@@ -8,10 +8,6 @@ import (
 
 // Handles when a client requests to spectate a multiplayer game
 func handleClientSpectateMultiplayerGame(user *sessions.User, packet *packets.ClientSpectateMultiplayerGame) {
-	if packet == nil {
-		return
-	}
-
 	game := multiplayer.GetGameByIdString(packet.GameId)
 
 	if game == nil {
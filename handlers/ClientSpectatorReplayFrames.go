@@ -7,9 +7,5 @@ import (
 
 // Handles when a client provides spectator replay frames
 func handleClientSpectatorReplayFrames(user *sessions.User, packet *packets.ClientSpectatorReplayFrames) {
-	if packet == nil {
-		return
-	}
-
 	user.HandleNewSpectatorFrames(packet)
 }
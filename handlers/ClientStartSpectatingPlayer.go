@@ -1,23 +1,33 @@
 package handlers
 
 import (
+	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"log"
 )
 
 // Handles when the client requests to start spectating a player.
 func handleClientStartSpectatingPlayer(user *sessions.User, packet *packets.ClientStartSpectatingPlayer) {
-	if packet == nil {
-		return
-	}
-
 	user.StopSpectatingAll()
 
 	spectatee := sessions.GetUserById(packet.UserId)
 
-	if spectatee == nil {
+	if spectatee == nil || !sessions.CanSeeOnline(user, spectatee) {
 		return
 	}
 
 	spectatee.AddSpectator(user)
+
+	// The spectator isn't added if either user blocked the other
+	if !utils.Includes(spectatee.GetSpectators(), user) {
+		return
+	}
+
+	err := db.InsertRecentPlayers([]int{user.Info.Id, spectatee.Info.Id}, db.RecentPlayerSpectator, "")
+
+	if err != nil {
+		log.Printf("Failed to insert recent players (#%v -> #%v) - %v\n", user.Info.Id, spectatee.Info.Id, err)
+	}
 }
@@ -8,11 +8,7 @@ import (
 
 // Handles when a user's client sends a status update
 func handleClientStatusUpdate(user *sessions.User, packet *packets.ClientStatusUpdate) {
-	if packet == nil {
-		return
-	}
-
-	if packet.Status == (objects.ClientStatus{}) {
+	if packet.Status == (objects.ClientStatus{}) || user.IsClientStatus(&packet.Status) {
 		return
 	}
 
@@ -7,9 +7,5 @@ import (
 
 // Handles when the client wishes to stop spectating the player
 func handleClientStopSpectatingPlayer(user *sessions.User, packet *packets.ClientStopSpectatingPlayer) {
-	if packet == nil {
-		return
-	}
-
 	user.StopSpectatingAll()
 }
@@ -10,10 +10,6 @@ import (
 
 // Handles when the client requests to unlink their twitch account
 func handleClientUnlinkTwitch(user *sessions.User, packet *packets.ClientUnlinkTwitch) {
-	if packet == nil {
-		return
-	}
-
 	user.Info.TwitchUsername = sql.NullString{}
 
 	err := db.UnlinkUserTwitch(user.Info.Id)
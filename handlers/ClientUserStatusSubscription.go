@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client subscribes/unsubscribes to the statuses of the users it is displaying
+func handleClientUserStatusSubscription(user *sessions.User, packet *packets.ClientUserStatusSubscription) {
+	switch packet.Action {
+	case packets.StatusSubscriptionActionSubscribe:
+		added := sessions.SubscribeToUserStatuses(user, packet.UserIds)
+
+		// Send the current statuses, so the client doesn't have to request them
+		statuses := getClientStatuses(user, added)
+
+		if len(statuses) > 0 {
+			sessions.SendPacketToUser(packets.NewServerUserStatus(statuses), user)
+		}
+	case packets.StatusSubscriptionActionUnsubscribe:
+		sessions.UnsubscribeFromUserStatuses(user, packet.UserIds)
+	}
+}
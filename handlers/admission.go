@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/utils"
+)
+
+// How often a queued login checks if it can be let in
+const loginAdmissionPollInterval = 50 * time.Millisecond
+
+// Queues logins so only a limited amount are processed at once, and only at a steady rate
+type loginAdmission struct {
+	slots     chan struct{}
+	bucket    *utils.TokenBucket
+	burst     int
+	perSecond float64
+	timeout   time.Duration
+}
+
+var (
+	admission      *loginAdmission
+	admissionMutex = &sync.Mutex{}
+)
+
+// Creates a login admission queue
+func newLoginAdmission(maxConcurrent int, burst int, perSecond float64, timeout time.Duration) *loginAdmission {
+	return &loginAdmission{
+		slots:     make(chan struct{}, maxConcurrent),
+		bucket:    utils.NewTokenBucket(burst, perSecond),
+		burst:     burst,
+		perSecond: perSecond,
+		timeout:   timeout,
+	}
+}
+
+// Returns if the queue was created with the given settings
+func (a *loginAdmission) matches(maxConcurrent int, burst int, perSecond float64, timeout time.Duration) bool {
+	return cap(a.slots) == maxConcurrent && a.burst == burst && a.perSecond == perSecond && a.timeout == timeout
+}
+
+// Returns the login admission queue. It is rebuilt whenever its settings change, so reloading the config takes effect.
+// Logins that were already let in keep their slot in the old queue until they're done.
+func getLoginAdmission() *loginAdmission {
+	settings := config.Get().LoginAdmission
+	timeout := settings.QueueTimeout.Duration()
+
+	admissionMutex.Lock()
+	defer admissionMutex.Unlock()
+
+	if admission == nil || !admission.matches(settings.MaxConcurrent, settings.Burst, settings.PerSecond, timeout) {
+		admission = newLoginAdmission(settings.MaxConcurrent, settings.Burst, settings.PerSecond, timeout)
+	}
+
+	return admission
+}
+
+// Waits until the login can be processed. Returns a function that must be called once the login is done,
+// and false if the login has waited longer than the queue timeout.
+func (a *loginAdmission) admit() (func(), bool) {
+	metrics.LoginQueue.Inc()
+	defer metrics.LoginQueue.Dec()
+
+	deadline := time.NewTimer(a.timeout)
+	defer deadline.Stop()
+
+	select {
+	case a.slots <- struct{}{}:
+	case <-deadline.C:
+		return nil, false
+	}
+
+	release := func() { <-a.slots }
+
+	for !a.bucket.Take() {
+		select {
+		case <-time.After(loginAdmissionPollInterval):
+		case <-deadline.C:
+			release()
+			return nil, false
+		}
+	}
+
+	return release, true
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"example.com/Quaver/Z/webhooks"
+)
+
+// Checks if the user is logging in from a banned ip address or machine. If they are, the connection is closed
+// and the login is reported to the anti-cheat webhook. Returns if the user is allowed to log in.
+func checkLoginBans(attempt *loginAttempt, user *db.User, hardware HardwareIds) (bool, error) {
+	hardwareBan, err := db.GetHardwareIdBan(hardware.CpuId, hardware.DiskId, hardware.CpuDiskId)
+
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	if hardwareBan != nil {
+		reason := fmt.Sprintf("Matched a banned %v id: `%v`", hardwareBan.Type, hardwareBan.HardwareId)
+		rejectBannedLogin(attempt, user, "Banned Machine", reason, hardwareBan.UserId, hardwareBan.Reason)
+		return false, nil
+	}
+
+	ipBan, err := db.GetIpBan(attempt.ip)
+
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	if ipBan != nil {
+		reason := fmt.Sprintf("Matched a banned ip: `%v`", ipBan.Ip)
+		rejectBannedLogin(attempt, user, "Banned IP", reason, ipBan.UserId, ipBan.Reason)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Disconnects a user that matched a ban and lets the anti-cheat team know about it
+func rejectBannedLogin(attempt *loginAttempt, user *db.User, title string, match string, bannedUserId sql.NullInt32, banReason string) {
+	sessions.SendPacketToConnection(packets.NewServerNotificationError("You are banned. You can appeal your ban at: discord.gg/quaver"), attempt.conn)
+	utils.CloseConnectionDelayed(attempt.conn)
+
+	log.Printf("[%v - #%v] Attempted to login, but they matched a ban (%v)\n", user.Username, user.Id, match)
+	attempt.recordFailure(loginFailureBanned)
+
+	text := match
+
+	// The ban belongs to another account, so this is likely someone evading their ban
+	if bannedUserId.Valid && int(bannedUserId.Int32) != user.Id {
+		bannedUser := &db.User{Id: int(bannedUserId.Int32)}
+		text += fmt.Sprintf("\nA different account is logging in from a banned machine. The ban belongs to: %v", bannedUser.GetProfileUrl())
+	}
+
+	if banReason != "" {
+		text += fmt.Sprintf("\nBan Reason: %v", banReason)
+	}
+
+	webhooks.SendAntiCheat(user.Username, user.Id, user.GetProfileUrl(), user.AvatarUrl.String, title, text)
+}
+
+// How often logins with an X-Forwarded-For header from an untrusted peer are warned about
+const untrustedForwardedForWarningInterval = time.Minute
+
+// The unix time in milliseconds of the last warning about an untrusted X-Forwarded-For header
+var lastUntrustedForwardedForWarning atomic.Int64
+
+// Returns the ip address of a user logging in. X-Forwarded-For is only used for connections from a trusted proxy,
+// because clients can send the header themselves.
+func getLoginIp(conn net.Conn, forwardedFor string) string {
+	ip := parseIp(conn.RemoteAddr().String())
+
+	if forwardedFor == "" {
+		return ip
+	}
+
+	if !isTrustedProxy(ip) {
+		warnUntrustedForwardedFor(ip)
+		return ip
+	}
+
+	// Each proxy appends the address it received the request from, so the client is the last hop that
+	// wasn't added by a trusted proxy. Anything to the left of it could have been sent by the client.
+	hops := strings.Split(forwardedFor, ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip = parseIp(hops[i])
+
+		if !isTrustedProxy(ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// Warns that a login came through a proxy that isn't trusted. If the server is behind a proxy that's missing from
+// server.trusted_proxies, every user appears to log in from its address, which breaks ip bans, login throttling
+// and linked accounts. Warnings are limited, so clients sending the header themselves can't flood the logs.
+func warnUntrustedForwardedFor(ip string) {
+	now := time.Now().UnixMilli()
+	last := lastUntrustedForwardedForWarning.Load()
+
+	if now-last < untrustedForwardedForWarningInterval.Milliseconds() || !lastUntrustedForwardedForWarning.CompareAndSwap(last, now) {
+		return
+	}
+
+	log.Printf("WARNING: Ignoring X-Forwarded-For from %v, which isn't in server.trusted_proxies. "+
+		"If the server is behind a proxy, every user will appear to log in from the proxy's address.\n", ip)
+}
+
+// Returns if an ip address belongs to one of the configured proxies
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range config.Get().Server.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(parsed) {
+				return true
+			}
+
+			continue
+		}
+
+		if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Strips the port from an address, so it can be compared to banned ips
+func parseIp(address string) string {
+	address = strings.TrimSpace(address)
+
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+
+	return address
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/webhooks"
+)
+
+// The score a linked account needs before it is reported. Sharing only an ip address isn't enough, as
+// people in the same household or behind the same network commonly do.
+const linkedAccountReportThreshold = db.LinkedAccountScoreDiskId
+
+const (
+	// How long a pair of accounts isn't reported again after being reported
+	linkedAccountReportExpiry = 24 * time.Hour
+
+	// The amount of remembered pairs before the expired ones are cleared out
+	linkedAccountReportPruneSize = 10_000
+)
+
+// Pairs of accounts that have already been reported and when, so they aren't reported on every login
+var (
+	reportedLinkedAccounts      = map[[2]int]time.Time{}
+	reportedLinkedAccountsMutex = &sync.Mutex{}
+)
+
+// Looks up other accounts that share the user's login fingerprints, and reports the likely alt accounts
+func detectLinkedAccounts(user *db.User) {
+	accounts, err := db.GetLinkedAccounts(user.Id)
+
+	if err != nil {
+		log.Printf("[%v #%v] Failed to look up linked accounts - %v\n", user.Username, user.Id, err)
+		return
+	}
+
+	accounts = filterReportableLinkedAccounts(user.Id, accounts)
+
+	if len(accounts) == 0 {
+		return
+	}
+
+	webhooks.SendAntiCheat(user.Username, user.Id, user.GetProfileUrl(), user.AvatarUrl.String, "Possible Alt Accounts", formatLinkedAccounts(accounts))
+}
+
+// Returns the linked accounts that score high enough to be reported and haven't been reported yet, highest score first
+func filterReportableLinkedAccounts(userId int, accounts []*db.LinkedAccount) []*db.LinkedAccount {
+	reportable := make([]*db.LinkedAccount, 0)
+
+	for _, account := range accounts {
+		if account.Score() < linkedAccountReportThreshold {
+			continue
+		}
+
+		if !markLinkedAccountsReported(userId, account.UserId) {
+			continue
+		}
+
+		reportable = append(reportable, account)
+	}
+
+	sort.SliceStable(reportable, func(i, j int) bool {
+		return reportable[i].Score() > reportable[j].Score()
+	})
+
+	return reportable
+}
+
+// Formats linked accounts into a list with their profile links and what they share
+func formatLinkedAccounts(accounts []*db.LinkedAccount) string {
+	formatted := ""
+
+	for i, account := range accounts {
+		var shared []string
+
+		if account.SharedCpuDiskId {
+			shared = append(shared, "CPU + Disk")
+		}
+
+		if account.SharedDiskId {
+			shared = append(shared, "Disk")
+		}
+
+		if account.SharedIp {
+			shared = append(shared, "IP")
+		}
+
+		banned := ""
+
+		if !account.Allowed {
+			banned = " **(Banned)**"
+		}
+
+		formatted += fmt.Sprintf("**%v. [%v](%v)**%v - Score: %v (%v)\n", i+1, account.Username, account.GetProfileUrl(),
+			banned, account.Score(), strings.Join(shared, ", "))
+	}
+
+	return formatted
+}
+
+// Remembers that two accounts were reported. Returns false if they were already reported recently.
+func markLinkedAccountsReported(a int, b int) bool {
+	reportedLinkedAccountsMutex.Lock()
+	defer reportedLinkedAccountsMutex.Unlock()
+
+	pair := linkedAccountPair(a, b)
+
+	if reportedAt, ok := reportedLinkedAccounts[pair]; ok && time.Since(reportedAt) < linkedAccountReportExpiry {
+		return false
+	}
+
+	if len(reportedLinkedAccounts) >= linkedAccountReportPruneSize {
+		pruneReportedLinkedAccounts()
+	}
+
+	reportedLinkedAccounts[pair] = time.Now()
+	return true
+}
+
+// Clears out the pairs that have expired. If there are still too many, they're all forgotten, which at worst
+// gets some of them reported again.
+func pruneReportedLinkedAccounts() {
+	for pair, reportedAt := range reportedLinkedAccounts {
+		if time.Since(reportedAt) >= linkedAccountReportExpiry {
+			delete(reportedLinkedAccounts, pair)
+		}
+	}
+
+	if len(reportedLinkedAccounts) >= linkedAccountReportPruneSize {
+		clear(reportedLinkedAccounts)
+	}
+}
+
+// Returns a key for two accounts that is the same regardless of their order
+func linkedAccountPair(a int, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+
+	return [2]int{a, b}
+}
@@ -15,6 +15,7 @@ import (
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/metrics"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
@@ -37,7 +38,10 @@ type LoginData struct {
 	Client string `json:"client"`
 
 	// Hardware string
-Hardware string `json:"hw,omitempty"`
+	Hardware string `json:"hw,omitempty"`
+
+	// The packet encoding the client would like to use (json or msgpack)
+	Encoding string `json:"enc,omitempty"`
 }
 
 type HardwareIds struct {
@@ -50,45 +54,120 @@ var (
 	restyClient = resty.New()
 )
 
-// HandleLogin Handles the login of a client
-func HandleLogin(conn net.Conn, r *http.Request) error {
+// Reasons logins fail for, used to label metrics
+const (
+	loginFailureInvalidData = "invalid_data"
+	loginFailureSteam       = "steam"
+	loginFailureNoAccount   = "no_account"
+	loginFailureBanned      = "banned"
+	loginFailureGameBuild   = "game_build"
+	loginFailureDatabase    = "database"
+	loginFailureSession     = "session"
+	loginFailureMaintenance = "maintenance"
+	loginFailureBusy        = "busy"
+	loginFailureThrottled   = "throttled"
+)
+
+// A login that is being processed, used to keep track of who failed to log in and why
+type loginAttempt struct {
+	conn    net.Conn
+	ip      string
+	steamId string
+	userId  int
+
+	// If the Steam ticket proved that the client owns the Steam id. Until then, the id is only what the client claims.
+	steamVerified bool
+}
+
+// HandleLogin Handles the login of a client. compression is whether the connection negotiated permessage-deflate.
+func HandleLogin(conn net.Conn, r *http.Request, compression bool) error {
+	attempt := &loginAttempt{conn: conn, ip: getLoginIp(conn, r.Header.Get("X-Forwarded-For"))}
+
+	if attempt.isThrottled("ip:" + attempt.ip) {
+		return nil
+	}
+
+	release, admitted := getLoginAdmission().admit()
+
+	if !admitted {
+		sessions.SendPacketToConnection(packets.NewServerNotificationError("The server is busy. Please try again in a moment."), conn)
+		utils.CloseConnectionDelayed(conn)
+		metrics.LoginFailures.WithLabelValues(loginFailureBusy).Inc()
+		return nil
+	}
+
+	defer release()
+
 	data, err := parseLoginData(r)
 
 	if err != nil {
-		return logFailedLogin(conn, err)
+		return attempt.fail(loginFailureInvalidData, err)
+	}
+
+	attempt.steamId = data.Id
+
+	if attempt.isThrottled("steam:" + attempt.steamId) {
+		return nil
 	}
 
 	err = authenticateSteamTicket(data)
 
 	if err != nil {
-		return logFailedLogin(conn, err)
+		return attempt.fail(loginFailureSteam, err)
 	}
 
+	attempt.steamVerified = true
+
 	user, err := db.GetUserBySteamId(data.Id)
 
+	if user != nil {
+		attempt.userId = user.Id
+	}
+
 	if err != nil {
 		// User does not exist yet, so prompt them to select a username for their account.
 		if err == sql.ErrNoRows {
 			sessions.SendPacketToConnection(packets.NewServerChooseUsername(), conn)
 			utils.CloseConnectionDelayed(conn)
 			log.Printf("[%v] %v logged in but does not have an account yet.\n", conn.RemoteAddr(), data.Id)
+			attempt.recordFailure(loginFailureNoAccount)
 			return nil
 		}
 
-		return err
+		return attempt.fail(loginFailureDatabase, err)
+	}
+
+	if user.IsBanExpired() {
+		err = db.UnbanUser(user.Id)
+
+		if err != nil {
+			return attempt.fail(loginFailureDatabase, err)
+		}
+
+		user.Allowed = true
+		log.Printf("[%v - #%v] Temporary ban has expired and has been lifted\n", user.Username, user.Id)
 	}
 
-	if !user.Allowed {
-		sessions.SendPacketToConnection(packets.NewServerNotificationError("You are banned. You can appeal your ban at: discord.gg/quaver"), conn)
+	if user.IsBanned() {
+		sessions.SendPacketToConnection(packets.NewServerNotificationError(user.GetBanMessage()), conn)
 		utils.CloseConnectionDelayed(conn)
 		log.Printf("[%v - #%v] Attempted to login, but they are banned\n", user.Username, user.Id)
+		attempt.recordFailure(loginFailureBanned)
+		return nil
+	}
+
+	if sessions.IsMaintenanceEnabled() && !sessions.CanLoginDuringMaintenance(user.UserGroups) {
+		sessions.SendPacketToConnection(packets.NewServerNotificationError(sessions.GetMaintenanceMessage()), conn)
+		utils.CloseConnectionDelayed(conn)
+		log.Printf("[%v - #%v] Attempted to login, but the server is in maintenance mode\n", user.Username, user.Id)
+		attempt.recordFailure(loginFailureMaintenance)
 		return nil
 	}
 
 	build, err := parseGameBuild(data.Client)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureGameBuild, err)
 	}
 
 	err = verifyGameBuild(build)
@@ -96,10 +175,11 @@ func HandleLogin(conn net.Conn, r *http.Request) error {
 	if err != nil {
 		if err == sql.ErrNoRows {
 			if !handleCustomGameBuildUsage(conn, user, data.Client) {
+				attempt.recordFailure(loginFailureGameBuild)
 				return nil
 			}
 		} else {
-			return err
+			return attempt.fail(loginFailureDatabase, err)
 		}
 	}
 
@@ -108,26 +188,29 @@ func HandleLogin(conn net.Conn, r *http.Request) error {
 	err = db.InsertLoginHardwareId(user.Id, hardware.CpuId, hardware.DiskId, hardware.CpuDiskId, build)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureDatabase, err)
 	}
 
-	ip := conn.RemoteAddr().String()
-	ipHeader := r.Header.Get("X-Forwarded-For")
+	err = db.InsertLoginIpAddress(user.Id, attempt.ip)
 
-	if ipHeader != "" {
-		ip = ipHeader
+	if err != nil {
+		return attempt.fail(loginFailureDatabase, err)
 	}
 
-	err = db.InsertLoginIpAddress(user.Id, ip)
+	allowed, err := checkLoginBans(attempt, user, hardware)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureDatabase, err)
+	}
+
+	if !allowed {
+		return nil
 	}
 
 	err = db.UpdateUserLatestActivity(user.Id)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureDatabase, err)
 	}
 
 	err = updateUserAvatar(user)
@@ -139,29 +222,59 @@ func HandleLogin(conn net.Conn, r *http.Request) error {
 	err = removePreviousLoginSession(user)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureSession, err)
 	}
 
 	sessionUser := sessions.NewUser(conn, user)
+	sessionUser.SetEncoding(packets.ParseEncoding(data.Encoding))
+	sessionUser.SetCompression(compression)
 
 	err = sessionUser.SetStats()
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureDatabase, err)
 	}
 
+	blocked, err := db.GetBlockedUsers(user.Id)
+
+	if err != nil {
+		return attempt.fail(loginFailureDatabase, err)
+	}
+
+	sessionUser.SetBlockedUsers(blocked)
+
+	friends, err := db.GetUserFriendsList(user.Id)
+
+	if err != nil {
+		return attempt.fail(loginFailureDatabase, err)
+	}
+
+	sessionUser.SetFriends(friends)
+
+	statusTimes, err := db.GetUserStatusTimes(user.Id)
+
+	if err != nil {
+		return attempt.fail(loginFailureDatabase, err)
+	}
+
+	sessionUser.SetPreviousStatusTimes(statusTimes)
+
 	err = sessions.AddUser(sessionUser)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureSession, err)
 	}
 
 	err = sendLoginPackets(sessionUser)
 
 	if err != nil {
-		return err
+		return attempt.fail(loginFailureSession, err)
 	}
 
+	attempt.succeed()
+	go detectLinkedAccounts(user)
+
+	metrics.Logins.Inc()
 	log.Printf("[%v #%v] Logged in (%v users online).\n", user.Username, user.Id, sessions.GetOnlineUserCount())
 	return nil
 }
@@ -192,14 +305,14 @@ func parseLoginData(r *http.Request) (*LoginData, error) {
 
 // Authenticates the user via Steam. Makes sure the user has a valid id and ticket
 func authenticateSteamTicket(data *LoginData) error {
-	if config.Instance.BypassSteamLogin {
+	if config.Get().BypassSteamLogin {
 		return nil
 	}
 
 	resp, err := restyClient.R().
 		SetQueryParams(map[string]string{
-			"key":    config.Instance.Steam.PublisherKey,
-			"appid":  strconv.Itoa(config.Instance.Steam.AppId),
+			"key":    config.Get().Steam.PublisherKey,
+			"appid":  strconv.Itoa(config.Get().Steam.AppId),
 			"ticket": strings.Replace(data.PTicket, "-", "", -1),
 		}).
 		Get("https://partner.steam-api.com/ISteamUserAuth/AuthenticateUserTicket/v1/")
@@ -256,14 +369,14 @@ func authenticateSteamTicket(data *LoginData) error {
 
 // Checks if the user actually owns the game on Steam
 func checkSteamAppOwnership(steamId string) error {
-	if config.Instance.BypassSteamLogin {
+	if config.Get().BypassSteamLogin {
 		return nil
 	}
 
 	resp, err := restyClient.R().
 		SetQueryParams(map[string]string{
-			"key":     config.Instance.Steam.PublisherKey,
-			"appid":   strconv.Itoa(config.Instance.Steam.AppId),
+			"key":     config.Get().Steam.PublisherKey,
+			"appid":   strconv.Itoa(config.Get().Steam.AppId),
 			"steamid": steamId,
 		}).
 		Get("https://partner.steam-api.com/ISteamUser/CheckAppOwnership/v2/")
@@ -392,7 +505,7 @@ func canUserUseCustomGameBuild(user *db.User) bool {
 
 // Sends webhook and disconnects a user for invalid client usage. Returns if the user is allowed to login
 func handleCustomGameBuildUsage(conn net.Conn, user *db.User, client string) bool {
-	if config.Instance.BypassSteamLogin {
+	if config.Get().BypassSteamLogin {
 		return true
 	}
 
@@ -412,7 +525,7 @@ func handleCustomGameBuildUsage(conn net.Conn, user *db.User, client string) boo
 
 // Updates the avatar for the user and sets the new one.
 func updateUserAvatar(user *db.User) error {
-	if config.Instance.BypassSteamLogin {
+	if config.Get().BypassSteamLogin {
 		return nil
 	}
 
@@ -435,13 +548,17 @@ func removePreviousLoginSession(user *db.User) error {
 		return nil
 	}
 
+	savePlaytime(u)
+
+	// Queued before the session is removed, so it's still written once the user's queue is stopped
+	sessions.SendPacketToUser(packets.NewServerNotificationError("You are being logged out due to logging in from a different location"), u)
+
 	err := sessions.RemoveUser(u)
 
 	if err != nil {
 		return err
 	}
 
-	sessions.SendPacketToUser(packets.NewServerNotificationError("You are being logged out due to logging in from a different location"), u)
 	utils.CloseConnectionDelayed(u.Conn)
 	return nil
 }
@@ -449,20 +566,17 @@ func removePreviousLoginSession(user *db.User) error {
 // Sends initial packets to log the user in
 func sendLoginPackets(user *sessions.User) error {
 	sessions.SendPacketToUser(packets.NewServerLoginReply(user.SerializeForPacket(), user.GetStatsSlice(), user.GetToken()), user)
-	sessions.SendPacketToUser(packets.NewServerUsersOnline(sessions.GetOnlineUserIds()), user)
-	sessions.SendPacketToUser(packets.NewServerUserInfo(sessions.GetSerializedOnlineUsers()), user)
+	sessions.SendPacketToUser(packets.NewServerUsersOnline(sessions.GetOnlineUserIds(user)), user)
+	sessions.SendPacketToUser(packets.NewServerUserInfo(sessions.GetSerializedOnlineUsers(user)), user)
 	sessions.SendPacketToUser(packets.NewServerTwitchConnection(user.Info.TwitchUsername.String), user)
-	sessions.SendPacketToAllUsers(packets.NewServerUserConnected(user.SerializeForPacket()))
+	sessions.SendPacketToUser(packets.NewServerPresence(user.GetPresence()), user)
+	sendUserConnected(user)
 	joinChatChannels(user)
 
-	friends, err := db.GetUserFriendsList(user.Info.Id)
-
-	if err != nil {
-		return err
-	}
+	sessions.SendPacketToUser(packets.NewServerFriendsList(user.GetFriends()), user)
+	sessions.SendPacketToUser(packets.NewServerBlockedUsersList(user.GetBlockedUsers()), user)
 
-	sessions.SendPacketToUser(packets.NewServerFriendsList(friends), user)
-	return nil
+	return sendPendingFriendRequests(user)
 }
 
 // Joins an available chat channel
@@ -499,7 +613,55 @@ func joinChatChannels(user *sessions.User) {
 	}
 }
 
-// Logs a generic login failure
-func logFailedLogin(conn net.Conn, err error) error {
-	return fmt.Errorf("[%v] login failed - %v", conn.RemoteAddr(), err)
+// Checks if the client has to back off from logging in. Throttled clients are notified and disconnected.
+func (attempt *loginAttempt) isThrottled(key string) bool {
+	wait := loginThrottler.check(key)
+
+	if wait <= 0 {
+		return false
+	}
+
+	message := fmt.Sprintf("You have failed to log in too many times. Please try again in %v seconds.", int(wait.Seconds())+1)
+
+	sessions.SendPacketToConnection(packets.NewServerNotificationError(message), attempt.conn)
+	utils.CloseConnectionDelayed(attempt.conn)
+	metrics.LoginFailures.WithLabelValues(loginFailureThrottled).Inc()
+	return true
+}
+
+// Records a failed login that was caused by an error, and wraps the error with the connection's address
+func (attempt *loginAttempt) fail(reason string, err error) error {
+	attempt.recordFailure(reason)
+	return fmt.Errorf("[%v] login failed - %v", attempt.conn.RemoteAddr(), err)
+}
+
+// Counts a failed login by its reason and stores it in the database. Failures that are the client's fault
+// count towards their backoff, and an alert is sent once a single ip fails too many times.
+func (attempt *loginAttempt) recordFailure(reason string) {
+	metrics.LoginFailures.WithLabelValues(reason).Inc()
+
+	if err := db.InsertLoginFailure(attempt.ip, attempt.steamId, attempt.userId, reason); err != nil {
+		log.Printf("[%v] Failed to insert login failure - %v\n", attempt.ip, err)
+	}
+
+	if !throttledLoginFailures[reason] {
+		return
+	}
+
+	failures, alert := loginThrottler.recordFailure("ip:" + attempt.ip)
+
+	// Anyone can claim someone else's Steam id, so only failures after it was verified count against it
+	if attempt.steamVerified {
+		loginThrottler.recordFailure("steam:" + attempt.steamId)
+	}
+
+	if alert {
+		go webhooks.SendLoginFailureAlert(attempt.ip, failures, reason)
+	}
+}
+
+// Forgets the client's failed logins after they've logged in successfully
+func (attempt *loginAttempt) succeed() {
+	loginThrottler.reset("ip:" + attempt.ip)
+	loginThrottler.reset("steam:" + attempt.steamId)
 }
@@ -1,6 +1,12 @@
 package handlers
 
-import "testing"
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"net"
+	"testing"
+	"time"
+)
 
 func TestParseHardwareIds(t *testing.T) {
 	tests := []struct {
@@ -65,3 +71,161 @@ func TestParseHardwareIds(t *testing.T) {
 		})
 	}
 }
+
+func TestParseIp(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.1:52314": "192.168.1.1",
+		"192.168.1.1":       "192.168.1.1",
+		" 203.0.113.5":      "203.0.113.5",
+		"[2001:db8::1]:443": "2001:db8::1",
+	}
+
+	for address, expected := range tests {
+		if ip := parseIp(address); ip != expected {
+			t.Fatalf("expected %v to be parsed as %v, got %v", address, expected, ip)
+		}
+	}
+}
+
+// A connection that only knows its remote address
+type remoteAddrConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c *remoteAddrConn) RemoteAddr() net.Addr { return c.addr }
+
+func TestGetLoginIp(t *testing.T) {
+	previous := config.Get()
+	config.Set(config.Default())
+	defer func() { config.Set(previous) }()
+
+	config.Get().Server.TrustedProxies = []string{"10.0.0.0/8"}
+
+	proxy := &remoteAddrConn{addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 52314}}
+	client := &remoteAddrConn{addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.7"), Port: 52314}}
+
+	tests := []struct {
+		name         string
+		conn         net.Conn
+		forwardedFor string
+		expected     string
+	}{
+		{"no header", client, "", "198.51.100.7"},
+		{"untrusted peer", client, "203.0.113.5", "198.51.100.7"},
+		{"trusted proxy", proxy, "203.0.113.5", "203.0.113.5"},
+		{"spoofed first hop", proxy, "192.0.2.1, 203.0.113.5", "203.0.113.5"},
+		{"chained proxies", proxy, "203.0.113.5, 10.0.0.2", "203.0.113.5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ip := getLoginIp(test.conn, test.forwardedFor); ip != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, ip)
+			}
+		})
+	}
+}
+
+func TestFilterReportableLinkedAccounts(t *testing.T) {
+	accounts := []*db.LinkedAccount{
+		{UserId: 1002, SharedIp: true},
+		{UserId: 1003, SharedDiskId: true},
+		{UserId: 1004, SharedCpuDiskId: true, SharedDiskId: true},
+	}
+
+	reportable := filterReportableLinkedAccounts(1001, accounts)
+
+	if len(reportable) != 2 || reportable[0].UserId != 1004 || reportable[1].UserId != 1003 {
+		t.Fatalf("expected accounts 1004 and 1003 to be reported, got %+v", reportable)
+	}
+
+	if len(filterReportableLinkedAccounts(1003, []*db.LinkedAccount{{UserId: 1001, SharedDiskId: true}})) != 0 {
+		t.Fatal("expected an already reported pair to not be reported again")
+	}
+}
+
+func TestReportedLinkedAccountsExpire(t *testing.T) {
+	if !markLinkedAccountsReported(2001, 2002) {
+		t.Fatal("expected a new pair to be reported")
+	}
+
+	reportedLinkedAccountsMutex.Lock()
+	reportedLinkedAccounts[linkedAccountPair(2001, 2002)] = time.Now().Add(-linkedAccountReportExpiry)
+	reportedLinkedAccountsMutex.Unlock()
+
+	if !markLinkedAccountsReported(2002, 2001) {
+		t.Fatal("expected an expired pair to be reported again")
+	}
+}
+
+func TestLoginAdmission(t *testing.T) {
+	admission := newLoginAdmission(1, 1, 1000, 100*time.Millisecond)
+
+	release, admitted := admission.admit()
+
+	if !admitted {
+		t.Fatal("expected the first login to be admitted")
+	}
+
+	// The only slot is taken, so the next login times out while waiting for it
+	if _, admitted := admission.admit(); admitted {
+		t.Fatal("expected the second login to time out")
+	}
+
+	release()
+
+	release, admitted = admission.admit()
+
+	if !admitted {
+		t.Fatal("expected a login to be admitted once the slot was released")
+	}
+
+	release()
+}
+
+func TestLoginThrottleBacksOff(t *testing.T) {
+	previous := config.Get()
+	config.Set(config.Default())
+	defer func() { config.Set(previous) }()
+
+	settings := &config.Get().LoginThrottle
+	settings.FreeAttempts = 2
+	settings.BaseBackoff = config.Duration(time.Second)
+	settings.MaxBackoff = config.Duration(5 * time.Second)
+	settings.AlertThreshold = 4
+
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+
+	for i, backoff := range expected {
+		if actual := getLoginBackoff(i + 1); actual != backoff {
+			t.Fatalf("expected a backoff of %v after %v failures, got %v", backoff, i+1, actual)
+		}
+	}
+
+	throttle := newLoginThrottle()
+
+	for i := 1; i <= 3; i++ {
+		if _, alert := throttle.recordFailure("ip:127.0.0.1"); alert {
+			t.Fatalf("expected no alert after %v failures", i)
+		}
+	}
+
+	if throttle.check("ip:127.0.0.1") <= 0 {
+		t.Fatal("expected the client to be throttled")
+	}
+
+	if _, alert := throttle.recordFailure("ip:127.0.0.1"); !alert {
+		t.Fatal("expected an alert once the threshold was reached")
+	}
+
+	if _, alert := throttle.recordFailure("ip:127.0.0.1"); alert {
+		t.Fatal("expected the alert to only be sent once")
+	}
+
+	throttle.reset("ip:127.0.0.1")
+
+	if throttle.check("ip:127.0.0.1") != 0 {
+		t.Fatal("expected the client to no longer be throttled")
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"example.com/Quaver/Z/config"
+)
+
+// The amount of tracked clients before the ones that haven't failed in a while are cleared out
+const loginThrottlePruneSize = 10_000
+
+// Failed logins that count towards a client's backoff. Failures caused by the server aren't the client's fault,
+// and users without an account yet need to be able to come back after choosing a username.
+var throttledLoginFailures = map[string]bool{
+	loginFailureInvalidData: true,
+	loginFailureSteam:       true,
+	loginFailureBanned:      true,
+	loginFailureGameBuild:   true,
+}
+
+// Keeps track of failed logins by ip address and Steam id, and makes clients wait longer after each one
+type loginThrottle struct {
+	clients map[string]*throttledClient
+	mutex   *sync.Mutex
+}
+
+type throttledClient struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+	alerted      bool
+}
+
+var loginThrottler = newLoginThrottle()
+
+// Creates a new login throttle
+func newLoginThrottle() *loginThrottle {
+	return &loginThrottle{
+		clients: map[string]*throttledClient{},
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Returns how long a client has to wait before they can try to log in again
+func (t *loginThrottle) check(key string) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	client := t.getClient(key)
+
+	if client == nil {
+		return 0
+	}
+
+	if remaining := time.Until(client.blockedUntil); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// Counts a failed login and backs off the client if they've used up their free attempts.
+// Returns the amount of failures and if an alert should be sent for the client.
+func (t *loginThrottle) recordFailure(key string) (int, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	settings := config.Get().LoginThrottle
+	client := t.getClient(key)
+
+	if client == nil {
+		if len(t.clients) >= loginThrottlePruneSize {
+			t.prune()
+		}
+
+		client = &throttledClient{}
+		t.clients[key] = client
+	}
+
+	client.failures++
+	client.lastFailure = time.Now()
+	client.blockedUntil = client.lastFailure.Add(getLoginBackoff(client.failures))
+
+	alert := !client.alerted && client.failures >= settings.AlertThreshold
+
+	if alert {
+		client.alerted = true
+	}
+
+	return client.failures, alert
+}
+
+// Forgets a client's failed logins
+func (t *loginThrottle) reset(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.clients, key)
+}
+
+// Returns a tracked client, forgetting them if they haven't failed in a while
+func (t *loginThrottle) getClient(key string) *throttledClient {
+	client, ok := t.clients[key]
+
+	if !ok {
+		return nil
+	}
+
+	if time.Since(client.lastFailure) >= config.Get().LoginThrottle.ResetAfter.Duration() {
+		delete(t.clients, key)
+		return nil
+	}
+
+	return client
+}
+
+// Clears out every client that hasn't failed in a while
+func (t *loginThrottle) prune() {
+	for key := range t.clients {
+		t.getClient(key)
+	}
+}
+
+// Returns how long a client has to wait after a given amount of failed logins. The wait doubles with every failure.
+func getLoginBackoff(failures int) time.Duration {
+	settings := config.Get().LoginThrottle
+	exceeded := failures - settings.FreeAttempts
+
+	if exceeded <= 0 {
+		return 0
+	}
+
+	backoff := settings.BaseBackoff.Duration()
+
+	for i := 1; i < exceeded && backoff < settings.MaxBackoff.Duration(); i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, settings.MaxBackoff.Duration())
+}
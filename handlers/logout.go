@@ -2,8 +2,8 @@ package handlers
 
 import (
 	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/multiplayer"
-	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
 	"log"
@@ -23,7 +23,13 @@ func HandleLogout(conn net.Conn) error {
 		chat.RemoveUserFromAllChannels(user)
 		multiplayer.RemoveUserFromLobby(user)
 
-		sessions.SendPacketToAllUsers(packets.NewServerUserDisconnected(user.Info.Id))
+		sendUserDisconnected(user)
+
+		if err := db.UpdateUserLatestActivity(user.Info.Id); err != nil {
+			log.Printf("[%v %v] Error while updating latest activity - %v\n", user.Info.Username, user.Info.Id, err)
+		}
+
+		savePlaytime(user)
 
 		err := sessions.RemoveUser(user)
 
@@ -37,3 +43,12 @@ func HandleLogout(conn net.Conn) error {
 	utils.CloseConnection(conn)
 	return nil
 }
+
+// Adds the time a user spent in each status during their session to their totals
+func savePlaytime(user *sessions.User) {
+	err := db.AddUserStatusTimes(user.Info.Id, user.GetSessionStatusTimes())
+
+	if err != nil {
+		log.Printf("[%v %v] Error while saving playtime - %v\n", user.Info.Username, user.Info.Id, err)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/sessions"
+	"github.com/prometheus/client_golang/prometheus"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+// Handlers that take longer than this are logged
+const slowPacketHandlerThreshold = 250 * time.Millisecond
+
+// packetMiddleware Wraps a packet handler with behaviour that is shared between packets
+type packetMiddleware func(next packetHandler) packetHandler
+
+// Applies middleware to a handler. The first middleware is the outermost one.
+func chainMiddleware(handler packetHandler, middleware ...packetMiddleware) packetHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// Recovers from panics in handlers, so a single bad packet can't take down the server
+func recoverMiddleware(next packetHandler) packetHandler {
+	return func(user *sessions.User, packet *incomingPacket) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[%v #%v] Recovered from panic while handling packet %v: %v\n%s\n",
+					user.Info.Username, user.Info.Id, packet.id, r, debug.Stack())
+
+				metrics.PacketHandlerPanics.WithLabelValues(metrics.PacketLabel(packet.id)).Inc()
+			}
+		}()
+
+		next(user, packet)
+	}
+}
+
+// Logs handlers that take too long to run
+func loggingMiddleware(next packetHandler) packetHandler {
+	return func(user *sessions.User, packet *incomingPacket) {
+		start := time.Now()
+		next(user, packet)
+
+		if elapsed := time.Since(start); elapsed >= slowPacketHandlerThreshold {
+			log.Printf("[%v #%v] Handling packet %v took %v\n", user.Info.Username, user.Info.Id, packet.id, elapsed)
+		}
+	}
+}
+
+// Drops packets from users that exceed their rate limit
+func rateLimitMiddleware(next packetHandler) packetHandler {
+	return func(user *sessions.User, packet *incomingPacket) {
+		if !checkPacketRateLimit(user, packet.id) {
+			return
+		}
+
+		next(user, packet)
+	}
+}
+
+// Records how often each packet is handled and how long it takes
+func metricsMiddleware(next packetHandler) packetHandler {
+	return func(user *sessions.User, packet *incomingPacket) {
+		label := metrics.PacketLabel(packet.id)
+		timer := prometheus.NewTimer(metrics.PacketHandlerDuration.WithLabelValues(label))
+
+		metrics.PacketsReceived.WithLabelValues(label).Inc()
+		defer timer.ObserveDuration()
+
+		next(user, packet)
+	}
+}
+
+// Counts the packet as activity, so the user isn't marked as away
+func trackActivity(next packetHandler) packetHandler {
+	return func(user *sessions.User, packet *incomingPacket) {
+		user.UpdateLastActivity()
+		next(user, packet)
+	}
+}
+
+// Only lets users through who are in any of the given user groups or have any of the given privileges
+func requirePermissions(groups common.UserGroups, privileges common.Privileges) packetMiddleware {
+	return func(next packetHandler) packetHandler {
+		return func(user *sessions.User, packet *incomingPacket) {
+			if !common.HasUserGroup(user.Info.UserGroups, groups) && !common.HasPrivilege(user.Info.Privileges, privileges) {
+				log.Printf("[%v #%v] Sent packet %v without the required permissions\n", user.Info.Username, user.Info.Id, packet.id)
+				return
+			}
+
+			next(user, packet)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+)
+
+func TestHandleSkipsPacketsThatFailToDecode(t *testing.T) {
+	called := false
+
+	handler := handle(func(user *sessions.User, packet *packets.ClientLobbyJoin) {
+		called = true
+	})
+
+	user := sessions.NewUser(nil, &db.User{Id: 1, Username: "User #1"})
+	handler(user, &incomingPacket{id: packets.PacketIdClientLobbyJoin, data: []byte("{"), encoding: packets.EncodingJSON})
+
+	if called {
+		t.Fatal("expected handler to not be called for a malformed packet")
+	}
+
+	handler(user, &incomingPacket{id: packets.PacketIdClientLobbyJoin, data: []byte(`{"id":0}`), encoding: packets.EncodingJSON})
+
+	if !called {
+		t.Fatal("expected handler to be called for a valid packet")
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	handler := chainMiddleware(func(user *sessions.User, packet *incomingPacket) {
+		panic("bad packet")
+	}, recoverMiddleware)
+
+	user := sessions.NewUser(nil, &db.User{Id: 1, Username: "User #1"})
+	handler(user, &incomingPacket{id: packets.PacketIdClientLobbyJoin})
+
+	if testutil.ToFloat64(metrics.PacketHandlerPanics.WithLabelValues(metrics.PacketLabel(packets.PacketIdClientLobbyJoin))) == 0 {
+		t.Fatal("expected the panic to be recorded")
+	}
+}
+
+func TestRequirePermissions(t *testing.T) {
+	called := false
+
+	handler := chainMiddleware(func(user *sessions.User, packet *incomingPacket) {
+		called = true
+	}, requirePermissions(common.UserGroupDonator, common.PrivilegeEnableTournamentMode))
+
+	handler(sessions.NewUser(nil, &db.User{Id: 1, UserGroups: common.UserGroupNormal, Privileges: common.PrivilegeNormal}), &incomingPacket{})
+
+	if called {
+		t.Fatal("expected users without the user group or privilege to be rejected")
+	}
+
+	handler(sessions.NewUser(nil, &db.User{Id: 2, UserGroups: common.UserGroupNormal | common.UserGroupDonator}), &incomingPacket{})
+
+	if !called {
+		t.Fatal("expected users in the user group to be let through")
+	}
+
+	called = false
+	handler(sessions.NewUser(nil, &db.User{Id: 3, Privileges: common.PrivilegeEnableTournamentMode}), &incomingPacket{})
+
+	if !called {
+		t.Fatal("expected users with the privilege to be let through")
+	}
+}
@@ -1,136 +1,201 @@
 package handlers
 
 import (
-	"encoding/json"
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/multiplayer"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"example.com/Quaver/Z/webhooks"
 	"fmt"
 	"log"
 	"net"
 )
 
+// An incoming packet that hasn't been decoded yet
+type incomingPacket struct {
+	id       packets.PacketId
+	data     []byte
+	encoding packets.Encoding
+}
+
+// packetHandler Handles a single incoming packet for a user
+type packetHandler func(user *sessions.User, packet *incomingPacket)
+
+// Middleware that runs for every packet. The first middleware is the outermost one.
+var globalPacketMiddleware = []packetMiddleware{
+	recoverMiddleware,
+	metricsMiddleware,
+	loggingMiddleware,
+	rateLimitMiddleware,
+}
+
+// Every packet the server is able to handle
+var packetHandlers = newPacketRegistry()
+
+// Creates the registry of packet handlers. To handle a new packet, add it here.
+func newPacketRegistry() map[packets.PacketId]packetHandler {
+	registry := map[packets.PacketId]packetHandler{
+		packets.PacketIdClientPong:                       handle(handleClientPong),
+		packets.PacketIdClientChatMessage:                handle(handleClientChatMessage, trackActivity),
+		packets.PacketIdClientStatusUpdate:               handle(handleClientStatusUpdate),
+		packets.PacketIdClientRequestUserInfo:            handle(handleClientRequestUserInfo),
+		packets.PacketIdClientRequestLeaveChatChannel:    handle(handleClientRequestLeaveChatChannel),
+		packets.PacketIdClientRequestJoinChatChannel:     handle(handleClientRequestJoinChatChannel),
+		packets.PacketIdClientRequestUserStatus:          handle(handleClientRequestUserStatus),
+		packets.PacketIdClientUserStatusSubscription:     handle(handleClientUserStatusSubscription),
+		packets.PacketIdClientSearchUsers:                handle(handleClientSearchUsers),
+		packets.PacketIdClientRequestRecentPlayers:       handle(handleClientRequestRecentPlayers),
+		packets.PacketIdClientRequestChatHistory:         handle(handleClientRequestChatHistory),
+		packets.PacketIdClientLobbyJoin:                  handle(handleClientLobbyJoin, trackActivity),
+		packets.PacketIdClientLobbyLeave:                 handle(handleClientLobbyLeave),
+		packets.PacketIdClientCreateGame:                 handle(handleClientCreateGame, trackActivity),
+		packets.PacketIdClientLeaveGame:                  handle(withGameLocked(handleClientLeaveGame)),
+		packets.PacketIdClientJoinGame:                   handle(handleClientJoinGame, trackActivity),
+		packets.PacketIdClientChangeGameMap:              handle(withGameLocked(handleClientChangeGameMap)),
+		packets.PacketIdClientGamePlayerNoMap:            handle(withGameLocked(handleClientGamePlayerNoMap)),
+		packets.PacketIdClientGamePlayerHasMap:           handle(withGameLocked(handleClientGamePlayerHasMap)),
+		packets.PacketIdClientGamePlayerReady:            handle(withGameLocked(handleClientGamePlayerReady), trackActivity),
+		packets.PacketIdClientGamePlayerNotReady:         handle(withGameLocked(handleClientGamePlayerNotReady)),
+		packets.PacketIdClientGameStartCountdown:         handle(withGameLocked(handleClientGameStartCountdown)),
+		packets.PacketIdClientGameStopCountdown:          handle(withGameLocked(handleClientGameStopCountdown)),
+		packets.PacketIdClientPacketChangeGameName:       handle(withGameLocked(handleClientChangeGameName)),
+		packets.PacketIdClientGameHostSelectingMap:       handle(withGameLocked(handleClientGameHostSelectingMap)),
+		packets.PacketIdClientPacketChangeGamePassword:   handle(withGameLocked(handleClientChangeGamePassword)),
+		packets.PacketIdClientGameChangeModifiers:        handle(withGameLocked(handleClientGameChangeModifiers)),
+		packets.PacketIdClientGameChangeFreeModType:      handle(withGameLocked(handleClientGameChangeFreeMod)),
+		packets.PacketIdClientGamePlayerChangeModifiers:  handle(withGameLocked(handleClientGameChangePlayerModifiers)),
+		packets.PacketIdClientGameChangeAutoHostRotation: handle(withGameLocked(handleClientGameHostRotation)),
+		packets.PacketIdClientGameChangeMaxPlayers:       handle(withGameLocked(handleClientGameChangeMaxPlayers)),
+		packets.PacketIdClientGameAcceptInvite:           handle(handleClientGameAcceptInvite),
+		packets.PacketIdClientRequestUserStats:           handle(handleClientRequestUserStats),
+		packets.PacketIdClientGameKickPlayer:             handle(withGameLocked(handleClientGameKickPlayer)),
+		packets.PacketIdClientGameTransferHost:           handle(withGameLocked(handleClientGameTransferHost)),
+		packets.PacketIdClientInviteToGame:               handle(withGame(handleClientGameInvite)),
+		packets.PacketIdClientGameScreenLoaded:           handle(withGameLocked(handleClientGameScreenLoaded), trackActivity),
+		packets.PacketIdClientPlayerFinished:             handle(withGameLocked(handleClientGamePlayerFinished), trackActivity),
+		packets.PacketIdClientGameSongSkipRequest:        handle(withGameLocked(handleClientGamePlayerSkipSong)),
+		packets.PacketIdClientGameJudgements:             handle(withGameLocked(handleClientGameJudgements), trackActivity),
+		packets.PacketIdClientFriendship:                 handle(handleClientFriendship),
+		packets.PacketIdClientBlockUser:                  handle(handleClientBlockUser),
+		packets.PacketIdClientChangePresence:             handle(handleClientChangePresence),
+		packets.PacketIdClientTwitchUnlink:               handle(handleClientUnlinkTwitch),
+		packets.PacketIdClientGameDifficultyRatings:      handle(withGame(handleClientGameDifficultyRatings)),
+		packets.PacketIdClientStartSpectatePlayer:        handle(handleClientStartSpectatingPlayer, trackActivity),
+		packets.PacketIdClientStopSpectatePlayer:         handle(handleClientStopSpectatingPlayer),
+		packets.PacketIdClientSpectatorReplayFrames:      handle(handleClientSpectatorReplayFrames, trackActivity),
+		packets.PacketIdClientSpectateMultiplayerGame:    handle(handleClientSpectateMultiplayerGame, requirePermissions(common.UserGroupDonator, common.PrivilegeEnableTournamentMode)),
+		packets.PacketIdClientGameAutoHost:               handle(withGame(handleClientGameAutoHost)),
+		packets.PacketIdClientLogout:                     handle(handleClientLogout),
+		packets.PacketIdClientGameChangeEnablePreview:    handle(withGameLocked(handleClientGameEnablePreview)),
+	}
+
+	for id, handler := range registry {
+		registry[id] = chainMiddleware(handler, globalPacketMiddleware...)
+	}
+
+	return registry
+}
+
 // HandleIncomingPackets Handles incoming messages from clients
-func HandleIncomingPackets(conn net.Conn, msg string) {
+func HandleIncomingPackets(conn net.Conn, msg []byte, encoding packets.Encoding) {
 	user := sessions.GetUserByConnection(conn)
 
 	if user == nil {
-		log.Printf("[%v] Received packet while not logged in: %v\n", conn.RemoteAddr(), msg)
+		log.Printf("[%v] Received packet while not logged in: %v\n", conn.RemoteAddr(), formatPacket(msg, encoding))
 		return
 	}
 
-	var p packets.Packet
+	id, err := encoding.PeekId(msg)
 
-	if err := json.Unmarshal([]byte(msg), &p); err != nil {
+	if err != nil {
 		log.Println(err)
 		return
 	}
 
-	switch p.Id {
-	case packets.PacketIdClientPong:
-		handleClientPong(user, unmarshalPacket[packets.ClientPong](msg))
-	case packets.PacketIdClientChatMessage:
-		handleClientChatMessage(user, unmarshalPacket[packets.ClientChatMessage](msg))
-	case packets.PacketIdClientStatusUpdate:
-		handleClientStatusUpdate(user, unmarshalPacket[packets.ClientStatusUpdate](msg))
-	case packets.PacketIdClientRequestUserInfo:
-		handleClientRequestUserInfo(user, unmarshalPacket[packets.ClientRequestUserInfo](msg))
-	case packets.PacketIdClientRequestLeaveChatChannel:
-		handleClientRequestLeaveChatChannel(user, unmarshalPacket[packets.ClientRequestLeaveChatChannel](msg))
-	case packets.PacketIdClientRequestJoinChatChannel:
-		handleClientRequestJoinChatChannel(user, unmarshalPacket[packets.ClientRequestJoinChatChannel](msg))
-	case packets.PacketIdClientRequestUserStatus:
-		handleClientRequestUserStatus(user, unmarshalPacket[packets.ClientRequestUserStatus](msg))
-	case packets.PacketIdClientLobbyJoin:
-		handleClientLobbyJoin(user, unmarshalPacket[packets.ClientLobbyJoin](msg))
-	case packets.PacketIdClientLobbyLeave:
-		handleClientLobbyLeave(user, unmarshalPacket[packets.ClientLobbyLeave](msg))
-	case packets.PacketIdClientCreateGame:
-		handleClientCreateGame(user, unmarshalPacket[packets.ClientCreateGame](msg))
-	case packets.PacketIdClientLeaveGame:
-		handleClientLeaveGame(user, unmarshalPacket[packets.ClientLeaveGame](msg))
-	case packets.PacketIdClientJoinGame:
-		handleClientJoinGame(user, unmarshalPacket[packets.ClientJoinGame](msg))
-	case packets.PacketIdClientChangeGameMap:
-		handleClientChangeGameMap(user, unmarshalPacket[packets.ClientChangeGameMap](msg))
-	case packets.PacketIdClientGamePlayerNoMap:
-		handleClientGamePlayerNoMap(user, unmarshalPacket[packets.ClientGamePlayerNoMap](msg))
-	case packets.PacketIdClientGamePlayerHasMap:
-		handleClientGamePlayerHasMap(user, unmarshalPacket[packets.ClientGamePlayerHasMap](msg))
-	case packets.PacketIdClientGamePlayerReady:
-		handleClientGamePlayerReady(user, unmarshalPacket[packets.ClientGamePlayerReady](msg))
-	case packets.PacketIdClientGamePlayerNotReady:
-		handleClientGamePlayerNotReady(user, unmarshalPacket[packets.ClientGamePlayerNotReady](msg))
-	case packets.PacketIdClientGameStartCountdown:
-		handleClientGameStartCountdown(user, unmarshalPacket[packets.ClientGameStartCountdown](msg))
-	case packets.PacketIdClientGameStopCountdown:
-		handleClientGameStopCountdown(user, unmarshalPacket[packets.ClientGameStopCountdown](msg))
-	case packets.PacketIdClientPacketChangeGameName:
-		handleClientChangeGameName(user, unmarshalPacket[packets.ClientChangeGameName](msg))
-	case packets.PacketIdClientGameHostSelectingMap:
-		handleClientGameHostSelectingMap(user, unmarshalPacket[packets.ClientGameHostSelectingMap](msg))
-	case packets.PacketIdClientPacketChangeGamePassword:
-		handleClientChangeGamePassword(user, unmarshalPacket[packets.ClientChangeGamePassword](msg))
-	case packets.PacketIdClientGameChangeModifiers:
-		handleClientGameChangeModifiers(user, unmarshalPacket[packets.ClientGameChangeModifiers](msg))
-	case packets.PacketIdClientGameChangeFreeModType:
-		handleClientGameChangeFreeMod(user, unmarshalPacket[packets.ClientGameFreeModTypeChanged](msg))
-	case packets.PacketIdClientGamePlayerChangeModifiers:
-		handleClientGameChangePlayerModifiers(user, unmarshalPacket[packets.ClientGameChangePlayerModifiers](msg))
-	case packets.PacketIdClientGameChangeAutoHostRotation:
-		handleClientGameHostRotation(user, unmarshalPacket[packets.ClientGameHostRotation](msg))
-	case packets.PacketIdClientGameChangeMaxPlayers:
-		handleClientGameChangeMaxPlayers(user, unmarshalPacket[packets.ClientGameChangeMaxPlayers](msg))
-	case packets.PacketIdClientGameAcceptInvite:
-		handleClientGameAcceptInvite(user, unmarshalPacket[packets.ClientGameAcceptInvite](msg))
-	case packets.PacketIdClientRequestUserStats:
-		handleClientRequestUserStats(user, unmarshalPacket[packets.ClientRequestUserStats](msg))
-	case packets.PacketIdClientGameKickPlayer:
-		handleClientGameKickPlayer(user, unmarshalPacket[packets.ClientGameKickPlayer](msg))
-	case packets.PacketIdClientGameTransferHost:
-		handleClientGameTransferHost(user, unmarshalPacket[packets.ClientGameTransferHost](msg))
-	case packets.PacketIdClientInviteToGame:
-		handleClientGameInvite(user, unmarshalPacket[packets.ClientGameInvite](msg))
-	case packets.PacketIdClientGameScreenLoaded:
-		handleClientGameScreenLoaded(user, unmarshalPacket[packets.ClientGameScreenLoaded](msg))
-	case packets.PacketIdClientPlayerFinished:
-		handleClientGamePlayerFinished(user, unmarshalPacket[packets.ClientGamePlayerFinished](msg))
-	case packets.PacketIdClientGameSongSkipRequest:
-		handleClientGamePlayerSkipSong(user, unmarshalPacket[packets.ClientGamePlayerSkipSong](msg))
-	case packets.PacketIdClientGameJudgements:
-		handleClientGameJudgements(user, unmarshalPacket[packets.ClientGameJudgements](msg))
-	case packets.PacketIdClientFriendship:
-		handleClientFriendship(user, unmarshalPacket[packets.ClientFriendship](msg))
-	case packets.PacketIdClientTwitchUnlink:
-		handleClientUnlinkTwitch(user, unmarshalPacket[packets.ClientUnlinkTwitch](msg))
-	case packets.PacketIdClientGameDifficultyRatings:
-		handleClientGameDifficultyRatings(user, unmarshalPacket[packets.ClientGameDifficultyRatings](msg))
-	case packets.PacketIdClientStartSpectatePlayer:
-		handleClientStartSpectatingPlayer(user, unmarshalPacket[packets.ClientStartSpectatingPlayer](msg))
-	case packets.PacketIdClientStopSpectatePlayer:
-		handleClientStopSpectatingPlayer(user, unmarshalPacket[packets.ClientStopSpectatingPlayer](msg))
-	case packets.PacketIdClientSpectatorReplayFrames:
-		handleClientSpectatorReplayFrames(user, unmarshalPacket[packets.ClientSpectatorReplayFrames](msg))
-	case packets.PacketIdClientSpectateMultiplayerGame:
-		handleClientSpectateMultiplayerGame(user, unmarshalPacket[packets.ClientSpectateMultiplayerGame](msg))
-	case packets.PacketIdClientGameAutoHost:
-		handleClientGameAutoHost(user, unmarshalPacket[packets.ClientGameAutoHost](msg))
-	case packets.PacketIdClientLogout:
-		handleClientLogout(user, unmarshalPacket[packets.ClientLogout](msg))
-	case packets.PacketIdClientGameChangeEnablePreview:
-		handleClientGameEnablePreview(user, unmarshalPacket[packets.ClientGameEnablePreview](msg))
-	default:
-		log.Println(fmt.Errorf("unknown packet: %v", msg))
+	handler, ok := packetHandlers[id]
+
+	if !ok {
+		log.Println(fmt.Errorf("unknown packet: %v", formatPacket(msg, encoding)))
+		return
+	}
+
+	handler(user, &incomingPacket{id: id, data: msg, encoding: encoding})
+}
+
+// Creates a handler that decodes a packet into its type before passing it on. Handlers are never called with
+// packets that failed to decode. Middleware that is specific to this packet can be passed in.
+func handle[T any](handler func(user *sessions.User, packet *T), middleware ...packetMiddleware) packetHandler {
+	decoded := func(user *sessions.User, packet *incomingPacket) {
+		data := unmarshalPacket[T](packet.data, packet.encoding)
+
+		if data == nil {
+			return
+		}
+
+		handler(user, data)
+	}
+
+	return chainMiddleware(decoded, middleware...)
+}
+
+// Adapts a handler that needs the multiplayer game the user is currently in. Packets are ignored if the user isn't in a game.
+func withGame[T any](handler func(user *sessions.User, game *multiplayer.Game, packet *T)) func(user *sessions.User, packet *T) {
+	return func(user *sessions.User, packet *T) {
+		game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+		if game == nil {
+			return
+		}
+
+		handler(user, game, packet)
 	}
 }
 
+// Same as withGame, but the handler is run while the game is locked
+func withGameLocked[T any](handler func(user *sessions.User, game *multiplayer.Game, packet *T)) func(user *sessions.User, packet *T) {
+	return withGame(func(user *sessions.User, game *multiplayer.Game, packet *T) {
+		game.RunLocked(func() {
+			handler(user, game, packet)
+		})
+	})
+}
+
+// Checks if the user is allowed to send a packet. Users that keep flooding packets are disconnected and reported.
+func checkPacketRateLimit(user *sessions.User, id packets.PacketId) bool {
+	switch user.CheckRateLimit(id) {
+	case sessions.RateLimitDropped:
+		return false
+	case sessions.RateLimitDisconnect:
+		log.Printf("[%v #%v] Disconnected for exceeding the rate limit of packet %v\n", user.Info.Username, user.Info.Id, id)
+
+		webhooks.SendAntiCheat(user.Info.Username, user.Info.Id, user.Info.GetProfileUrl(), user.Info.AvatarUrl.String,
+			"Packet Flooding", fmt.Sprintf("Exceeded the rate limit of packet `%v` and was disconnected.", id))
+
+		utils.CloseConnection(user.Conn)
+		return false
+	}
+
+	return true
+}
+
 // unmarshalPacket Unmarshal a packet of a specified type
-func unmarshalPacket[T any](packet string) *T {
+func unmarshalPacket[T any](packet []byte, encoding packets.Encoding) *T {
 	var data T
 
-	if err := json.Unmarshal([]byte(packet), &data); err != nil {
+	if err := encoding.Unmarshal(packet, &data); err != nil {
 		log.Println(err)
 		return nil
 	}
 
 	return &data
 }
+
+// Returns a packet in a format that can be logged
+func formatPacket(msg []byte, encoding packets.Encoding) string {
+	if encoding.IsBinary() {
+		return fmt.Sprintf("%x", msg)
+	}
+
+	return string(msg)
+}
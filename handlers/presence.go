@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+)
+
+// Tells the users who are able to see it that a user has come online
+func sendUserConnected(user *sessions.User) {
+	sessions.SendPacketToUsers(packets.NewServerUserConnected(user.SerializeForPacket()), getPresenceViewers(user)...)
+}
+
+// Tells the users who are able to see it that a user has gone offline
+func sendUserDisconnected(user *sessions.User) {
+	sessions.SendPacketToUsers(packets.NewServerUserDisconnected(user.Info.Id), getPresenceViewers(user)...)
+}
+
+// Returns the other online users who are able to see that a user is online
+func getPresenceViewers(user *sessions.User) []*sessions.User {
+	var viewers []*sessions.User
+
+	for _, viewer := range sessions.GetOnlineUsers() {
+		if viewer != user && sessions.CanSeeOnline(viewer, user) {
+			viewers = append(viewers, viewer)
+		}
+	}
+
+	return viewers
+}
+
+// Runs a change that affects who is able to see a user (their presence or friends list), and tells the online users
+// who gained or lost sight of them that the user has come online or gone offline.
+func updatePresence(user *sessions.User, change func()) {
+	users := sessions.GetOnlineUsers()
+	before := make(map[*sessions.User]bool, len(users))
+
+	for _, viewer := range users {
+		before[viewer] = sessions.CanSeeOnline(viewer, user)
+	}
+
+	change()
+
+	// The user isn't online yet, so there's no one to tell
+	if sessions.GetUserById(user.Info.Id) != user {
+		return
+	}
+
+	var connected, disconnected []*sessions.User
+
+	for _, viewer := range users {
+		if viewer == user {
+			continue
+		}
+
+		canSee := sessions.CanSeeOnline(viewer, user)
+
+		switch {
+		case canSee && !before[viewer]:
+			connected = append(connected, viewer)
+		case !canSee && before[viewer]:
+			disconnected = append(disconnected, viewer)
+		}
+	}
+
+	sessions.SendPacketToUsers(packets.NewServerUserConnected(user.SerializeForPacket()), connected...)
+	sessions.SendPacketToUsers(packets.NewServerUserDisconnected(user.Info.Id), disconnected...)
+
+	// Users who can no longer see the user aren't able to keep spectating them
+	for _, spectator := range user.GetSpectators() {
+		if utils.Includes(disconnected, spectator) {
+			user.RemoveSpectator(spectator)
+		}
+	}
+}
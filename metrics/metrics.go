@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+)
+
+const namespace = "z"
+
+var (
+	OnlineUsers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "online_users",
+		Help:      "The amount of users that are currently online",
+	})
+
+	Logins = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "The amount of successful logins",
+	})
+
+	LoginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "The amount of failed logins by reason",
+	}, []string{"reason"})
+
+	UsersByStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "users_by_status",
+		Help:      "The amount of online users in each client status",
+	}, []string{"status"})
+
+	LoginQueue = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "login_queue",
+		Help:      "The amount of logins waiting to be admitted",
+	})
+
+	OutboundQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbound_queue_depth",
+		Help:      "The total amount of packets waiting to be written to the connections of online users",
+	})
+
+	OutboundQueueMaxDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbound_queue_max_depth",
+		Help:      "The amount of packets waiting in the fullest outbound queue",
+	})
+
+	PacketsReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "packets_received_total",
+		Help:      "The amount of packets received from clients by packet id",
+	}, []string{"id"})
+
+	PacketsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "packets_sent_total",
+		Help:      "The amount of packets sent to clients by packet id",
+	}, []string{"id"})
+
+	PacketHandlerPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "packet_handler_panics_total",
+		Help:      "The amount of panics that were recovered from while handling packets by packet id",
+	}, []string{"id"})
+
+	PacketHandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "packet_handler_duration_seconds",
+		Help:      "How long it takes to handle packets by packet id",
+		Buckets:   []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1},
+	}, []string{"id"})
+
+	OpenGames = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "multiplayer_open_games",
+		Help:      "The amount of multiplayer games in the lobby",
+	})
+
+	MatchesInProgress = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "multiplayer_matches_in_progress",
+		Help:      "The amount of multiplayer games that are currently playing a match",
+	})
+
+	ChatMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chat_messages_total",
+		Help:      "The amount of chat messages sent by channel. Private, multiplayer, spectator and clan chats are grouped by type.",
+	}, []string{"channel"})
+
+	RedisMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_subscriber_messages_total",
+		Help:      "The amount of messages received by the redis subscriber by channel",
+	}, []string{"channel"})
+
+	RedisLastMessageTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "redis_subscriber_last_message_timestamp_seconds",
+		Help:      "The unix time of the last message the redis subscriber received",
+	})
+
+	RedisHandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_subscriber_handler_duration_seconds",
+		Help:      "How long the redis subscriber takes to handle a message by channel. Messages are handled one at a time, so this is the lag added to the next message.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"channel"})
+
+	SQLErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sql_errors_total",
+		Help:      "The amount of failed SQL queries by operation",
+	}, []string{"operation"})
+)
+
+// Handler Returns the http handler that serves the metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// PacketLabel Returns the label value for a packet id
+func PacketLabel[T ~int](id T) string {
+	return strconv.Itoa(int(id))
+}
@@ -11,6 +11,7 @@ import (
 
 	"example.com/Quaver/Z/chat"
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/sessions"
@@ -261,7 +262,8 @@ func handleCommandMaxPlayers(user *sessions.User, game *Game, args []string) str
 	}
 
 	if len(args) < 3 {
-		return "You must provide a number between 2 and 16 in order to change the max player count."
+		return fmt.Sprintf("You must provide a number between %v and %v in order to change the max player count.",
+			config.Get().Multiplayer.MinPlayers, config.Get().Multiplayer.MaxPlayers)
 	}
 
 	numPlayers, err := strconv.Atoi(args[2])
@@ -9,7 +9,9 @@ import (
 
 	"example.com/Quaver/Z/chat"
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/metrics"
 	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/scoring"
@@ -207,13 +209,9 @@ func (game *Game) KickPlayer(requester *sessions.User, userId int) {
 	sessions.SendPacketToUser(packets.NewServerGameKicked(), user)
 }
 
-// AddSpectator Adds a spectator to the game.
+// AddSpectator Adds a spectator to the game. Only donators and users who can enable tournament mode are able to
+// spectate, which is checked when the packet is handled.
 func (game *Game) AddSpectator(user *sessions.User, password string) {
-	// Require the user to have either Donator or EnableTournamentMode in order to spectate
-	if !common.HasUserGroup(user.Info.UserGroups, common.UserGroupDonator) && !common.HasPrivilege(user.Info.Privileges, common.PrivilegeEnableTournamentMode) {
-		return
-	}
-
 	if (game.Data.HasPassword && game.Password != password) && !common.IsSwan(user.Info.UserGroups) {
 		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorPassword), user)
 		return
@@ -351,7 +349,7 @@ func (game *Game) SetPlayerNotReady(userId int) {
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
-// StartCountdown Starts the 5-second multiplayer countdown
+// StartCountdown Starts the multiplayer countdown
 func (game *Game) StartCountdown(requester *sessions.User) {
 	if game.Data.InProgress {
 		return
@@ -361,14 +359,16 @@ func (game *Game) StartCountdown(requester *sessions.User) {
 		return
 	}
 
-	game.countdownTimer = time.AfterFunc(5*time.Second, func() {
+	countdown := config.Get().Multiplayer.CountdownLength.Duration()
+
+	game.countdownTimer = time.AfterFunc(countdown, func() {
 		game.RunLocked(func() {
 			game.StartGame()
 		})
 	})
 
-	game.sendBotMessage("The countdown has started. The match will start in 5 seconds.")
-	game.sendPacketToPlayers(packets.NewServerGameStartCountdown())
+	game.sendBotMessage(fmt.Sprintf("The countdown has started. The match will start in %v.", countdown))
+	game.sendPacketToPlayers(packets.NewServerGameStartCountdown(countdown))
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
@@ -413,6 +413,7 @@ func (game *Game) StartGame() {
 	}
 
 	game.Data.InProgress = true
+	metrics.MatchesInProgress.Inc()
 
 	game.playersInMatch = utils.Filter(game.Data.PlayerIds, func(x int) bool {
 		return x != game.Data.RefereeId && !utils.Includes(game.Data.PlayersWithoutMap, x)
@@ -453,6 +454,7 @@ func (game *Game) EndGame(force bool) {
 	game.rotateHost()
 
 	game.Data.InProgress = false
+	metrics.MatchesInProgress.Dec()
 	game.playersInMatch = []int{}
 	game.playersScreenLoaded = []int{}
 	game.playersFinished = []int{}
@@ -689,7 +691,7 @@ func (game *Game) SetMaxPlayerCount(requester *sessions.User, count int) {
 
 // SendInvite Sends an invitation to a user in the multiplayer game
 func (game *Game) SendInvite(sender *sessions.User, user *sessions.User) {
-	if user == nil {
+	if user == nil || sessions.IsBlockedBetween(sender, user) {
 		return
 	}
 
@@ -796,7 +798,12 @@ func (game *Game) HandlePlayerJudgements(userId int, judgements []common.Judgeme
 		game.cachePlayerScore(userId, score)
 	}
 
-	packet := packets.NewServerGameJudgements(userId, judgements, mineHitDelta)
+	packet, err := sessions.NewPreparedPacket(packets.NewServerGameJudgements(userId, judgements, mineHitDelta))
+
+	if err != nil {
+		log.Printf("Failed to prepare judgements packet for game #%v - %v\n", game.Data.Id, err)
+		return
+	}
 
 	for _, playerId := range game.playersInMatch {
 		if playerId == userId {
@@ -916,6 +923,37 @@ func (game *Game) disband() {
 		return
 	}
 
+	game.removeFromLobby()
+}
+
+// Disband Removes every player and spectator from the game and disbands it, even if it is in tournament mode
+func (game *Game) Disband() {
+	for _, id := range append([]int{}, game.spectators...) {
+		if game.isDisbanded {
+			return
+		}
+
+		game.RemovePlayer(id)
+	}
+
+	for _, id := range append([]int{}, game.Data.PlayerIds...) {
+		if game.isDisbanded {
+			return
+		}
+
+		game.KickPlayer(nil, id)
+	}
+
+	if game.isDisbanded {
+		return
+	}
+
+	game.EndGame(true)
+	game.removeFromLobby()
+}
+
+// Removes the game and its chat channel from the lobby
+func (game *Game) removeFromLobby() {
 	game.isDisbanded = true
 	game.deleteCachedMatchSettings()
 	chat.RemoveMultiplayerChannel(game.Data.GameId)
@@ -1069,6 +1107,18 @@ func (game *Game) insertMatchIntoDatabase() {
 		return
 	}
 
+	playerIds := make([]int, 0, len(game.playerScores))
+
+	for userId := range game.playerScores {
+		playerIds = append(playerIds, userId)
+	}
+
+	err = db.InsertRecentPlayers(playerIds, db.RecentPlayerMultiplayer, game.Data.Name)
+
+	if err != nil {
+		log.Printf("Failed to insert recent players from game #%v into database - %v\n", game.Data.Id, err)
+	}
+
 	for userId, score := range game.playerScores {
 		winResult, _ := game.checkPlayerWinResult(userId)
 
@@ -1197,6 +1247,13 @@ func (game *Game) changeMapFromDbSong(song *db.SongMap) {
 
 // Sends a packet to all players in the game.
 func (game *Game) sendPacketToPlayers(packet interface{}) {
+	prepared, err := sessions.NewPreparedPacket(packet)
+
+	if err != nil {
+		log.Printf("Failed to prepare packet for game #%v - %v\n", game.Data.Id, err)
+		return
+	}
+
 	for _, id := range game.Data.PlayerIds {
 		user := sessions.GetUserById(id)
 
@@ -1204,7 +1261,7 @@ func (game *Game) sendPacketToPlayers(packet interface{}) {
 			continue
 		}
 
-		sessions.SendPacketToUser(packet, user)
+		sessions.SendPacketToUser(prepared, user)
 	}
 
 	for _, id := range game.spectators {
@@ -1219,7 +1276,7 @@ func (game *Game) sendPacketToPlayers(packet interface{}) {
 			continue
 		}
 
-		sessions.SendPacketToUser(packet, user)
+		sessions.SendPacketToUser(prepared, user)
 	}
 }
 
@@ -1239,7 +1296,7 @@ func (game *Game) validateAndCacheSettings() {
 	}
 
 	data.HasPassword = game.Password != ""
-	data.MaxPlayers = utils.Clamp(data.MaxPlayers, 2, 16)
+	data.MaxPlayers = utils.Clamp(data.MaxPlayers, config.Get().Multiplayer.MinPlayers, config.Get().Multiplayer.MaxPlayers)
 	data.Ruleset = objects.MultiplayerGameRulesetFreeForAll
 	data.FreeModType = utils.Clamp(data.FreeModType, objects.MultiplayerGameFreeModNone, objects.MultiplayerGameFreeModRegular|objects.MultiplayerGameFreeModRate)
 
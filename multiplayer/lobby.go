@@ -1,6 +1,7 @@
 package multiplayer
 
 import (
+	"example.com/Quaver/Z/metrics"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"log"
@@ -54,6 +55,7 @@ func AddGameToLobby(game *Game) {
 	defer lobby.mutex.Unlock()
 
 	lobby.games[game.Data.Id] = game
+	metrics.OpenGames.Set(float64(len(lobby.games)))
 	sendLobbyUsersGameInfoPacket(game, false)
 
 	log.Printf("Multiplayer Game `%v (#%v)` was created.\n", game.Data.Name, game.Data.Id)
@@ -64,14 +66,27 @@ func RemoveGameFromLobby(game *Game) {
 	lobby.mutex.Lock()
 	defer lobby.mutex.Unlock()
 
-	for _, user := range lobby.users {
-		sessions.SendPacketToUser(packets.NewServerGameDisbanded(game.Data.GameId), user)
-	}
+	sessions.SendPacketToUsers(packets.NewServerGameDisbanded(game.Data.GameId), getLobbyUsers()...)
 
 	delete(lobby.games, game.Data.Id)
+	metrics.OpenGames.Set(float64(len(lobby.games)))
 	log.Printf("Multiplayer game `%v (%v)` was disbanded.\n", game.Data.Name, game.Data.Id)
 }
 
+// GetGames Returns every multiplayer game in the lobby
+func GetGames() []*Game {
+	lobby.mutex.Lock()
+	defer lobby.mutex.Unlock()
+
+	games := make([]*Game, 0, len(lobby.games))
+
+	for _, game := range lobby.games {
+		games = append(games, game)
+	}
+
+	return games
+}
+
 // GetGameById Retrieves a multiplayer game by its id
 func GetGameById(id int) *Game {
 	lobby.mutex.Lock()
@@ -102,9 +117,16 @@ func sendLobbyUsersGameInfoPacket(game *Game, lock bool) {
 		defer lobby.mutex.Unlock()
 	}
 
-	packet := packets.NewServerMultiplayerGameInfo(game.Data)
+	sessions.SendPacketToUsers(packets.NewServerMultiplayerGameInfo(game.Data), getLobbyUsers()...)
+}
+
+// Returns a slice of the users in the lobby. The lobby mutex must be held by the caller.
+func getLobbyUsers() []*sessions.User {
+	users := make([]*sessions.User, 0, len(lobby.users))
 
 	for _, user := range lobby.users {
-		sessions.SendPacketToUser(packet, user)
+		users = append(users, user)
 	}
+
+	return users
 }
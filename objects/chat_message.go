@@ -0,0 +1,12 @@
+package objects
+
+// ChatMessage A message that was previously sent in a chat channel
+type ChatMessage struct {
+	Id                    int    `json:"id"`
+	SenderId              int    `json:"sid"`
+	SenderName            string `json:"u"`
+	SenderClanTag         string `json:"sct,omitempty"`
+	SenderClanAccentColor string `json:"sca,omitempty"`
+	Message               string `json:"m"`
+	Time                  int64  `json:"ts"`
+}
@@ -14,8 +14,37 @@ const (
 	ClientStatusInLobby
 	ClientStatusMultiplayer
 	ClientStatusListening
+	ClientStatusAway
 )
 
+// String Returns the name of the status type, used in logs and the admin API
+func (t ClientStatusType) String() string {
+	switch t {
+	case ClientStatusInMenus:
+		return "in_menus"
+	case ClientStatusSelecting:
+		return "selecting"
+	case ClientStatusPLaying:
+		return "playing"
+	case ClientStatusPaused:
+		return "paused"
+	case ClientStatusWatching:
+		return "watching"
+	case ClientStatusEditing:
+		return "editing"
+	case ClientStatusInLobby:
+		return "in_lobby"
+	case ClientStatusMultiplayer:
+		return "multiplayer"
+	case ClientStatusListening:
+		return "listening"
+	case ClientStatusAway:
+		return "away"
+	default:
+		return "unknown"
+	}
+}
+
 type ClientStatus struct {
 	Status    ClientStatusType `json:"s"`
 	MapId     int              `json:"mid"`
@@ -0,0 +1,20 @@
+package objects
+
+// Presence Who is able to see that a user is online and what they're doing
+type Presence int
+
+const (
+	// PresenceEveryone Everyone can see the user and their status
+	PresenceEveryone Presence = iota
+
+	// PresenceFriendsOnlyStatus Everyone can see the user is online, but only their friends can see their status
+	PresenceFriendsOnlyStatus
+
+	// PresenceAppearOffline The user appears offline to everyone except their friends
+	PresenceAppearOffline
+)
+
+// IsValid Returns if the presence is one of the known values
+func (p Presence) IsValid() bool {
+	return p >= PresenceEveryone && p <= PresenceAppearOffline
+}
@@ -15,3 +15,19 @@ type PacketUser struct {
 	ClanTag         string            `json:"ct,omitempty"`
 	ClanAccentColor string            `json:"ca,omitempty"`
 }
+
+// SearchedUser A user that was found by a username search. Offline users include the last time they were seen.
+type SearchedUser struct {
+	User     *PacketUser `json:"u"`
+	Online   bool        `json:"o"`
+	LastSeen int64       `json:"ls,omitempty"`
+}
+
+// RecentPlayer A user that someone has recently played with or spectated
+type RecentPlayer struct {
+	UserId    int    `json:"id"`
+	Username  string `json:"u"`
+	Type      int    `json:"t"`
+	GameName  string `json:"g,omitempty"`
+	Timestamp int64  `json:"ts"`
+}
@@ -0,0 +1,14 @@
+package packets
+
+type ClientBlockUser struct {
+	Packet
+	UserId int             `json:"u"`
+	Action BlockListAction `json:"a"`
+}
+
+type BlockListAction int
+
+const (
+	BlockListActionBlock BlockListAction = iota
+	BlockListActionUnblock
+)
@@ -0,0 +1,8 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ClientChangePresence struct {
+	Packet
+	Presence objects.Presence `json:"p"`
+}
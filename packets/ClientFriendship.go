@@ -11,4 +11,6 @@ type FriendsListAction int
 const (
 	FriendsListActionAdd FriendsListAction = iota
 	FriendsListActionRemove
+	FriendsListActionAcceptRequest
+	FriendsListActionDeclineRequest
 )
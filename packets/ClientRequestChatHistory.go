@@ -0,0 +1,9 @@
+package packets
+
+type ClientRequestChatHistory struct {
+	Packet
+	Channel string `json:"c"`
+
+	// The id of the oldest message the client has, so older messages are sent
+	Before int `json:"b"`
+}
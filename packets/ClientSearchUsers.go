@@ -0,0 +1,6 @@
+package packets
+
+type ClientSearchUsers struct {
+	Packet
+	Query string `json:"q"`
+}
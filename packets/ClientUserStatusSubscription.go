@@ -0,0 +1,14 @@
+package packets
+
+type ClientUserStatusSubscription struct {
+	Packet
+	UserIds []int                    `json:"u"`
+	Action  StatusSubscriptionAction `json:"a"`
+}
+
+type StatusSubscriptionAction int
+
+const (
+	StatusSubscriptionActionSubscribe StatusSubscriptionAction = iota
+	StatusSubscriptionActionUnsubscribe
+)
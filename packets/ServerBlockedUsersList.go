@@ -0,0 +1,13 @@
+package packets
+
+type ServerBlockedUsersList struct {
+	Packet
+	UserIds []int `json:"u"`
+}
+
+func NewServerBlockedUsersList(userIds []int) *ServerBlockedUsersList {
+	return &ServerBlockedUsersList{
+		Packet:  Packet{Id: PacketIdServerBlockedUsersList},
+		UserIds: userIds,
+	}
+}
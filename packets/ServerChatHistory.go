@@ -0,0 +1,21 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerChatHistory struct {
+	Packet
+	Channel  string                 `json:"c"`
+	Messages []*objects.ChatMessage `json:"m"` // Oldest first
+
+	// The id to request older messages with, or 0 if there are none
+	Next int `json:"n"`
+}
+
+func NewServerChatHistory(channel string, messages []*objects.ChatMessage, next int) *ServerChatHistory {
+	return &ServerChatHistory{
+		Packet:   Packet{Id: PacketIdServerChatHistory},
+		Channel:  channel,
+		Messages: messages,
+		Next:     next,
+	}
+}
@@ -0,0 +1,15 @@
+package packets
+
+type ServerFriendRequest struct {
+	Packet
+	UserId   int    `json:"u"`
+	Username string `json:"n"`
+}
+
+func NewServerFriendRequest(userId int, username string) *ServerFriendRequest {
+	return &ServerFriendRequest{
+		Packet:   Packet{Id: PacketIdServerFriendRequest},
+		UserId:   userId,
+		Username: username,
+	}
+}
@@ -7,9 +7,9 @@ type ServerGameStartCountdown struct {
 	Timestamp int64 `json:"t"`
 }
 
-func NewServerGameStartCountdown() *ServerGameStartCountdown {
+func NewServerGameStartCountdown(countdown time.Duration) *ServerGameStartCountdown {
 	return &ServerGameStartCountdown{
 		Packet:    Packet{PacketIdServerGameStartCountdown},
-		Timestamp: time.Now().UnixMilli() + 5000,
+		Timestamp: time.Now().Add(countdown).UnixMilli(),
 	}
 }
@@ -0,0 +1,15 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerPresence struct {
+	Packet
+	Presence objects.Presence `json:"p"`
+}
+
+func NewServerPresence(presence objects.Presence) *ServerPresence {
+	return &ServerPresence{
+		Packet:   Packet{Id: PacketIdServerPresence},
+		Presence: presence,
+	}
+}
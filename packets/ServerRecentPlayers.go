@@ -0,0 +1,15 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerRecentPlayers struct {
+	Packet
+	Players []*objects.RecentPlayer `json:"p"`
+}
+
+func NewServerRecentPlayers(players []*objects.RecentPlayer) *ServerRecentPlayers {
+	return &ServerRecentPlayers{
+		Packet:  Packet{Id: PacketIdServerRecentPlayers},
+		Players: players,
+	}
+}
@@ -0,0 +1,17 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerSearchUsersResults struct {
+	Packet
+	Query string                  `json:"q"`
+	Users []*objects.SearchedUser `json:"u"`
+}
+
+func NewServerSearchUsersResults(query string, users []*objects.SearchedUser) *ServerSearchUsersResults {
+	return &ServerSearchUsersResults{
+		Packet: Packet{Id: PacketIdServerSearchUsersResults},
+		Query:  query,
+		Users:  users,
+	}
+}
@@ -3,16 +3,21 @@ package packets
 import (
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
 )
 
 type ServerUserStats struct {
 	Packet
 	Stats map[int]map[common.Mode]*db.PacketUserStats `json:"u"`
+
+	// The total seconds each user has spent in each client status
+	Playtime map[int]map[objects.ClientStatusType]int64 `json:"pt"`
 }
 
-func NewServerUserStats(stats map[int]map[common.Mode]*db.PacketUserStats) *ServerUserStats {
+func NewServerUserStats(stats map[int]map[common.Mode]*db.PacketUserStats, playtime map[int]map[objects.ClientStatusType]int64) *ServerUserStats {
 	return &ServerUserStats{
-		Packet: Packet{Id: PacketIdServerUserStats},
-		Stats:  stats,
+		Packet:   Packet{Id: PacketIdServerUserStats},
+		Stats:    stats,
+		Playtime: playtime,
 	}
 }
@@ -0,0 +1,156 @@
+package packets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"strings"
+)
+
+// Encoding The wire format packets are serialized with. Clients negotiate it at login.
+type Encoding int
+
+const (
+	EncodingJSON Encoding = iota
+	EncodingMessagePack
+)
+
+// ParseEncoding Returns the encoding for the name a client provides at login. Unknown names fall back to JSON.
+func ParseEncoding(name string) Encoding {
+	switch strings.ToLower(name) {
+	case "msgpack", "messagepack":
+		return EncodingMessagePack
+	default:
+		return EncodingJSON
+	}
+}
+
+// String Returns the name of the encoding
+func (e Encoding) String() string {
+	switch e {
+	case EncodingMessagePack:
+		return "msgpack"
+	default:
+		return "json"
+	}
+}
+
+// IsBinary Returns if packets in this encoding are sent as binary frames
+func (e Encoding) IsBinary() bool {
+	return e == EncodingMessagePack
+}
+
+// Marshal Serializes a packet. MessagePack uses the same field names as the json tags.
+func (e Encoding) Marshal(v interface{}) ([]byte, error) {
+	switch e {
+	case EncodingMessagePack:
+		var buf bytes.Buffer
+
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		enc.UseCompactInts(true)
+
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// Unmarshal Deserializes a packet
+func (e Encoding) Unmarshal(data []byte, v interface{}) error {
+	switch e {
+	case EncodingMessagePack:
+		dec := msgpack.NewDecoder(bytes.NewReader(data))
+		dec.SetCustomStructTag("json")
+
+		return dec.Decode(v)
+	default:
+		return json.Unmarshal(data, v)
+	}
+}
+
+// PeekId Reads the id of a packet without decoding the rest of it.
+// The id is the first field of every packet, so this usually stops after the first key.
+func (e Encoding) PeekId(data []byte) (PacketId, error) {
+	switch e {
+	case EncodingMessagePack:
+		return peekMessagePackId(data)
+	default:
+		return peekJSONId(data)
+	}
+}
+
+// Scans the top level keys of a JSON packet until the id is found
+func peekJSONId(data []byte) (PacketId, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return PacketIdInvalid, fmt.Errorf("packet is not a json object")
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+
+		if err != nil {
+			return PacketIdInvalid, err
+		}
+
+		if key, ok := token.(string); ok && key == "id" {
+			var id PacketId
+
+			if err := dec.Decode(&id); err != nil {
+				return PacketIdInvalid, err
+			}
+
+			return id, nil
+		}
+
+		var skip json.RawMessage
+
+		if err := dec.Decode(&skip); err != nil {
+			return PacketIdInvalid, err
+		}
+	}
+
+	return PacketIdInvalid, fmt.Errorf("packet does not have an id")
+}
+
+// Scans the top level keys of a MessagePack packet until the id is found
+func peekMessagePackId(data []byte) (PacketId, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+
+	length, err := dec.DecodeMapLen()
+
+	if err != nil {
+		return PacketIdInvalid, fmt.Errorf("packet is not a msgpack map - %v", err)
+	}
+
+	for i := 0; i < length; i++ {
+		key, err := dec.DecodeString()
+
+		if err != nil {
+			return PacketIdInvalid, err
+		}
+
+		if key == "id" {
+			id, err := dec.DecodeInt()
+
+			if err != nil {
+				return PacketIdInvalid, err
+			}
+
+			return PacketId(id), nil
+		}
+
+		if err := dec.Skip(); err != nil {
+			return PacketIdInvalid, err
+		}
+	}
+
+	return PacketIdInvalid, fmt.Errorf("packet does not have an id")
+}
@@ -0,0 +1,72 @@
+package packets
+
+import (
+	"example.com/Quaver/Z/common"
+	"fmt"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	for _, encoding := range []Encoding{EncodingJSON, EncodingMessagePack} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			packet := ClientGameJudgements{
+				Packet:       Packet{Id: PacketIdClientGameJudgements},
+				Judgements:   []common.Judgements{common.JudgementMarv, common.JudgementMiss},
+				MineHitDelta: 12,
+			}
+
+			data, err := encoding.Marshal(packet)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			id, err := encoding.PeekId(data)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if id != PacketIdClientGameJudgements {
+				t.Fatalf("expected packet id %v, got %v", PacketIdClientGameJudgements, id)
+			}
+
+			var decoded ClientGameJudgements
+
+			if err := encoding.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.Id != packet.Id || decoded.MineHitDelta != packet.MineHitDelta || len(decoded.Judgements) != len(packet.Judgements) {
+				t.Fatalf("expected %+v, got %+v", packet, decoded)
+			}
+		})
+	}
+}
+
+func TestPeekIdNotFirstField(t *testing.T) {
+	data := fmt.Sprintf(`{"s":2,"f":null,"id":%v,"a":1.5}`, PacketIdClientSpectatorReplayFrames)
+	id, err := EncodingJSON.PeekId([]byte(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != PacketIdClientSpectatorReplayFrames {
+		t.Fatalf("expected packet id %v, got %v", PacketIdClientSpectatorReplayFrames, id)
+	}
+
+	if _, err := EncodingJSON.PeekId([]byte(`{"s":2}`)); err == nil {
+		t.Fatal("expected an error for a packet without an id")
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	if ParseEncoding("msgpack") != EncodingMessagePack {
+		t.Fatal("expected msgpack encoding")
+	}
+
+	if ParseEncoding("") != EncodingJSON || ParseEncoding("protobuf") != EncodingJSON {
+		t.Fatal("expected unknown encodings to fall back to json")
+	}
+}
@@ -3,3 +3,8 @@ package packets
 type Packet struct {
 	Id PacketId `json:"id"`
 }
+
+// GetPacketId Returns the id of the packet
+func (p Packet) GetPacketId() PacketId {
+	return p.Id
+}
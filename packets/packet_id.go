@@ -141,4 +141,16 @@ const (
 	PacketIdClientLogout
 	PacketIdClientGameChangeEnablePreview
 	PacketIdServerGameEnablePreviewChanged
+	PacketIdServerFriendRequest
+	PacketIdClientBlockUser
+	PacketIdServerBlockedUsersList
+	PacketIdClientChangePresence
+	PacketIdServerPresence
+	PacketIdClientUserStatusSubscription
+	PacketIdClientSearchUsers
+	PacketIdServerSearchUsersResults
+	PacketIdClientRequestRecentPlayers
+	PacketIdServerRecentPlayers
+	PacketIdClientRequestChatHistory
+	PacketIdServerChatHistory
 )
@@ -0,0 +1,103 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/objects"
+	"time"
+)
+
+// GetLastActivityTimestamp Returns the last time the user did something meaningful
+func (u *User) GetLastActivityTimestamp() int64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.lastActivityTimestamp
+}
+
+// UpdateLastActivity Marks the user as active. Users who were away get their previous status back.
+func (u *User) UpdateLastActivity() {
+	u.Mutex.Lock()
+	u.lastActivityTimestamp = time.Now().UnixMilli()
+	previous := u.statusBeforeAway
+
+	if previous != nil {
+		u.addStatusTime()
+		u.status = previous
+		u.statusBeforeAway = nil
+		u.awayRepliedTo = nil
+	}
+
+	u.Mutex.Unlock()
+
+	if previous != nil {
+		u.onClientStatusChanged()
+		u.SendClientStatusToSpectators()
+	}
+}
+
+// IsAway Returns if the user has been marked as away
+func (u *User) IsAway() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.statusBeforeAway != nil
+}
+
+// IsClientStatus Returns if the user's status is the same as a given one. The status the user had
+// before being marked as away is used, so clients re-sending their status doesn't count as activity.
+func (u *User) IsClientStatus(status *objects.ClientStatus) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	if u.statusBeforeAway != nil {
+		return *u.statusBeforeAway == *status
+	}
+
+	return *u.status == *status
+}
+
+// CheckAway Marks the user as away if they haven't been active for the given duration.
+// Users who are in the middle of playing aren't marked, since gameplay isn't always reported to the server.
+// Returns if the user has been marked as away.
+func (u *User) CheckAway(after time.Duration) bool {
+	u.Mutex.Lock()
+
+	if u.statusBeforeAway != nil || u.status.Status == objects.ClientStatusPLaying ||
+		time.Now().UnixMilli()-u.lastActivityTimestamp < after.Milliseconds() {
+		u.Mutex.Unlock()
+		return false
+	}
+
+	away := *u.status
+	away.Status = objects.ClientStatusAway
+
+	u.addStatusTime()
+	u.statusBeforeAway = u.status
+	u.status = &away
+	u.Mutex.Unlock()
+
+	u.onClientStatusChanged()
+	u.SendClientStatusToSpectators()
+	return true
+}
+
+// ShouldSendAwayReply Returns if a user who messaged this user should be told that they're away.
+// Each user is only told once until the user comes back.
+func (u *User) ShouldSendAwayReply(userId int) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	if u.statusBeforeAway == nil {
+		return false
+	}
+
+	if u.awayRepliedTo == nil {
+		u.awayRepliedTo = map[int]struct{}{}
+	}
+
+	if _, ok := u.awayRepliedTo[userId]; ok {
+		return false
+	}
+
+	u.awayRepliedTo[userId] = struct{}{}
+	return true
+}
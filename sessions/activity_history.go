@@ -0,0 +1,74 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/objects"
+	"sync"
+	"time"
+)
+
+const (
+	// ActivitySampleInterval How often the amount of users in each status is sampled
+	ActivitySampleInterval = time.Minute
+
+	// The amount of samples that are kept (a day's worth)
+	activityHistoryLength = 24 * 60
+)
+
+// ActivitySample The amount of users that were online and in each status at a point in time
+type ActivitySample struct {
+	Timestamp int64          `json:"timestamp"`
+	Online    int            `json:"online"`
+	Statuses  map[string]int `json:"statuses"`
+}
+
+var (
+	// mutex used for thread-safe access to the activity history
+	activityMutex = &sync.Mutex{}
+
+	// The recorded samples, oldest first
+	activityHistory = make([]*ActivitySample, 0, activityHistoryLength)
+)
+
+// RecordActivitySample Records the amount of users that are currently online and in each status.
+// Only the most recent day of samples is kept.
+func RecordActivitySample() *ActivitySample {
+	sample := &ActivitySample{
+		Timestamp: time.Now().UnixMilli(),
+		Statuses:  map[string]int{},
+	}
+
+	for _, user := range GetOnlineUsers() {
+		if common.HasUserGroup(user.Info.UserGroups, common.UserGroupBot) {
+			continue
+		}
+
+		sample.Online++
+		sample.Statuses[user.GetClientStatus().Status.String()]++
+	}
+
+	for status := objects.ClientStatusInMenus; status <= objects.ClientStatusAway; status++ {
+		metrics.UsersByStatus.WithLabelValues(status.String()).Set(float64(sample.Statuses[status.String()]))
+	}
+
+	activityMutex.Lock()
+	defer activityMutex.Unlock()
+
+	if len(activityHistory) >= activityHistoryLength {
+		activityHistory = append(activityHistory[:0], activityHistory[1:]...)
+	}
+
+	activityHistory = append(activityHistory, sample)
+	return sample
+}
+
+// GetActivityHistory Returns the recorded activity samples, oldest first
+func GetActivityHistory() []*ActivitySample {
+	activityMutex.Lock()
+	defer activityMutex.Unlock()
+
+	history := make([]*ActivitySample, len(activityHistory))
+	copy(history, activityHistory)
+	return history
+}
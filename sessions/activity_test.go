@@ -0,0 +1,49 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"testing"
+	"time"
+)
+
+func TestCheckAway(t *testing.T) {
+	_ = config.Load("../config.json")
+
+	if config.Get() == nil {
+		return
+	}
+
+	db.InitializeRedis()
+
+	user := NewUser(nil, &db.User{Id: 1, Username: "User #1", UserGroups: common.UserGroupNormal})
+	status := *user.GetClientStatus()
+
+	if user.CheckAway(time.Hour) {
+		t.Fatal("expected an active user to not be marked as away")
+	}
+
+	if !user.CheckAway(0) || !user.IsAway() || user.GetClientStatus().Status != objects.ClientStatusAway {
+		t.Fatal("expected the user to be marked as away")
+	}
+
+	if !user.IsClientStatus(&status) {
+		t.Fatal("expected the status before being away to be compared")
+	}
+
+	if !user.ShouldSendAwayReply(2) || user.ShouldSendAwayReply(2) {
+		t.Fatal("expected a single away reply per user")
+	}
+
+	user.UpdateLastActivity()
+
+	if user.IsAway() || *user.GetClientStatus() != status {
+		t.Fatal("expected the previous status to be restored on activity")
+	}
+
+	if user.ShouldSendAwayReply(2) {
+		t.Fatal("expected no away reply once the user is back")
+	}
+}
@@ -0,0 +1,111 @@
+package sessions
+
+import (
+	"bytes"
+	"compress/flate"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"github.com/gobwas/ws/wsflate"
+	"golang.org/x/exp/slices"
+	"io"
+)
+
+// The default size in bytes a packet needs to be before it gets compressed
+const defaultCompressionThreshold = 512
+
+// Returns if a packet should be compressed for users that negotiated permessage-deflate
+func shouldCompressPacket(id packets.PacketId, size int) bool {
+	settings := config.Get()
+
+	if settings == nil || !settings.Compression.Enabled {
+		return false
+	}
+
+	threshold := settings.Compression.Threshold
+
+	if threshold <= 0 {
+		threshold = defaultCompressionThreshold
+	}
+
+	if size < threshold {
+		return false
+	}
+
+	// No packet types specified means that every packet is eligible
+	if len(settings.Compression.PacketIds) == 0 {
+		return true
+	}
+
+	return slices.Contains(settings.Compression.PacketIds, int(id))
+}
+
+// Serializes a packet into a frame. The frame is compressed if the user negotiated
+// permessage-deflate and the packet is eligible for compression.
+func encodeFrame(data interface{}, encoding packets.Encoding, compression bool) (outboundFrame, error) {
+	j, err := encodePacket(data, encoding)
+
+	if err != nil {
+		return outboundFrame{}, err
+	}
+
+	frame := newOutboundFrame(j, encoding)
+
+	if !compression || !shouldCompressPacket(getPacketId(data), len(j)) {
+		return frame, nil
+	}
+
+	compressed, err := compressPacket(data, encoding, j)
+
+	// Sending the packet uncompressed is always valid, so fall back to it
+	if err != nil {
+		return frame, nil
+	}
+
+	frame.data = compressed
+	frame.compressed = true
+	return frame, nil
+}
+
+// Compresses a serialized packet. Connections negotiate no context takeover,
+// so every message is compressed on its own and prepared packets only need to be compressed once.
+func compressPacket(data interface{}, encoding packets.Encoding, serialized []byte) ([]byte, error) {
+	if prepared, ok := data.(*PreparedPacket); ok {
+		return prepared.compress(encoding)
+	}
+
+	return compress(serialized)
+}
+
+// Compresses a message with permessage-deflate. The stream is only flushed and never closed, because closing
+// it writes a final block which wsflate rejects. The flush marker is stripped by the writer as per RFC 7692.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := wsflate.NewWriter(&buf, func(w io.Writer) wsflate.Compressor {
+		f, _ := flate.NewWriter(w, flate.BestSpeed)
+		return f
+	})
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Returns the id of a packet if it has one
+func getPacketId(data interface{}) packets.PacketId {
+	if prepared, ok := data.(*PreparedPacket); ok {
+		data = prepared.packet
+	}
+
+	if packet, ok := data.(interface{ GetPacketId() packets.PacketId }); ok {
+		return packet.GetPacketId()
+	}
+
+	return packets.PacketIdInvalid
+}
@@ -0,0 +1,81 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"net"
+	"testing"
+)
+
+func TestCompressedPacketRoundTrip(t *testing.T) {
+	previous := config.Get()
+	config.Set(&config.Configuration{})
+	config.Get().Compression.Enabled = true
+	config.Get().Compression.Threshold = 1
+	defer func() { config.Set(previous) }()
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	user := NewUser(server, &db.User{Id: 1, Username: "User #1"})
+	user.SetCompression(true)
+	user.startOutbound()
+	defer user.stopOutbound()
+
+	packet := newBenchmarkPacket()
+	SendPacketToUsers(packet, user)
+
+	frame, err := ws.ReadFrame(client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if compressed, _ := wsflate.IsCompressed(frame.Header); !compressed {
+		t.Fatal("expected frame to be compressed")
+	}
+
+	data, err := wsflate.DefaultHelper.Decompress(frame.Payload)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := encodePacket(packet, packets.EncodingJSON)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != string(expected) {
+		t.Fatal("expected decompressed packet to be identical to the uncompressed packet")
+	}
+}
+
+func TestShouldCompressPacket(t *testing.T) {
+	previous := config.Get()
+	config.Set(&config.Configuration{})
+	defer func() { config.Set(previous) }()
+
+	if shouldCompressPacket(packets.PacketIdServerSpectatorReplayFrames, 4096) {
+		t.Fatal("expected no compression while disabled")
+	}
+
+	config.Get().Compression.Enabled = true
+	config.Get().Compression.PacketIds = []int{int(packets.PacketIdServerSpectatorReplayFrames)}
+
+	if !shouldCompressPacket(packets.PacketIdServerSpectatorReplayFrames, 4096) {
+		t.Fatal("expected configured packet above the threshold to be compressed")
+	}
+
+	if shouldCompressPacket(packets.PacketIdServerSpectatorReplayFrames, 16) {
+		t.Fatal("expected packet below the threshold to not be compressed")
+	}
+
+	if shouldCompressPacket(packets.PacketIdServerPing, 4096) {
+		t.Fatal("expected packet that isn't configured to not be compressed")
+	}
+}
@@ -0,0 +1,68 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/utils"
+	"log"
+	"sync/atomic"
+)
+
+// If the server is in maintenance mode. It starts off as the config value, and can be toggled while the server is running.
+var maintenance atomic.Bool
+
+// IsMaintenanceEnabled Returns if the server is in maintenance mode
+func IsMaintenanceEnabled() bool {
+	return maintenance.Load()
+}
+
+// SetMaintenanceMode Enables or disables maintenance mode. When enabled, online users that aren't allowed
+// to log in during maintenance are notified and disconnected.
+func SetMaintenanceMode(enabled bool) {
+	if maintenance.Swap(enabled) == enabled {
+		return
+	}
+
+	if !enabled {
+		log.Println("Maintenance mode has been disabled")
+		return
+	}
+
+	log.Println("Maintenance mode has been enabled")
+
+	for _, user := range GetOnlineUsers() {
+		if CanLoginDuringMaintenance(user.Info.UserGroups) {
+			continue
+		}
+
+		SendPacketToUser(packets.NewServerNotificationError(GetMaintenanceMessage()), user)
+		utils.CloseConnectionDelayed(user.Conn)
+	}
+}
+
+// CanLoginDuringMaintenance Returns if users in the given groups are allowed on the server during maintenance
+func CanLoginDuringMaintenance(userGroups common.UserGroups) bool {
+	if common.HasUserGroup(userGroups, common.UserGroupBot) {
+		return true
+	}
+
+	settings := config.Get()
+
+	if settings == nil {
+		return false
+	}
+
+	return userGroups&common.UserGroups(settings.Maintenance.AllowedUserGroups) != 0
+}
+
+// GetMaintenanceMessage Returns the notification sent to users who can't log in during maintenance
+func GetMaintenanceMessage() string {
+	settings := config.Get()
+
+	if settings == nil || settings.Maintenance.Message == "" {
+		return config.Default().Maintenance.Message
+	}
+
+	return settings.Maintenance.Message
+}
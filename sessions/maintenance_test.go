@@ -0,0 +1,27 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"testing"
+)
+
+func TestCanLoginDuringMaintenance(t *testing.T) {
+	previous := config.Get()
+	config.Set(config.Default())
+	defer func() { config.Set(previous) }()
+
+	config.Get().Maintenance.AllowedUserGroups = common.UserGroupAdmin | common.UserGroupDeveloper
+
+	if !CanLoginDuringMaintenance(common.UserGroupNormal | common.UserGroupDeveloper) {
+		t.Fatal("expected developers to be able to log in")
+	}
+
+	if CanLoginDuringMaintenance(common.UserGroupNormal | common.UserGroupDonator) {
+		t.Fatal("expected normal users to not be able to log in")
+	}
+
+	if !CanLoginDuringMaintenance(common.UserGroupBot) {
+		t.Fatal("expected bots to always be able to log in")
+	}
+}
@@ -0,0 +1,264 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/packets"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OutboundOverflowPolicy What happens to a user when their outbound queue is full
+type OutboundOverflowPolicy string
+
+const (
+	OutboundOverflowDrop       OutboundOverflowPolicy = "drop"
+	OutboundOverflowDisconnect OutboundOverflowPolicy = "disconnect"
+)
+
+const (
+	defaultOutboundQueueSize = 256
+	outboundWriteTimeout     = 10 * time.Second
+)
+
+const (
+	outboundStateIdle int32 = iota
+	outboundStateRunning
+	outboundStateStopped
+)
+
+// OutboundQueueStats A snapshot of the outbound queues of every online user
+type OutboundQueueStats struct {
+	Users       int   `json:"users"`
+	TotalDepth  int   `json:"total_depth"`
+	MaxDepth    int   `json:"max_depth"`
+	Dropped     int64 `json:"dropped"`
+	Disconnects int64 `json:"disconnects"`
+}
+
+var (
+	// Total amount of packets that were dropped due to full queues
+	outboundDropped int64
+
+	// Total amount of users that were disconnected due to full queues
+	outboundDisconnects int64
+)
+
+// A serialized packet and the websocket opcode it is sent with
+type outboundFrame struct {
+	op         ws.OpCode
+	data       []byte
+	compressed bool
+}
+
+// Creates a frame for a packet serialized with a given encoding
+func newOutboundFrame(data []byte, encoding packets.Encoding) outboundFrame {
+	frame := outboundFrame{op: ws.OpText, data: data}
+
+	if encoding.IsBinary() {
+		frame.op = ws.OpBinary
+	}
+
+	return frame
+}
+
+// Writes a frame to a connection
+func (f outboundFrame) writeTo(conn net.Conn) error {
+	if !f.compressed {
+		return wsutil.WriteServerMessage(conn, f.op, f.data)
+	}
+
+	// Compressed messages are marked with the first reserved bit
+	frame := ws.NewFrame(f.op, true, f.data)
+	frame.Header.Rsv = ws.Rsv(true, false, false)
+
+	return ws.WriteFrame(conn, frame)
+}
+
+// A bounded queue of packets waiting to be written to a user's connection by a dedicated writer goroutine.
+// Broadcasting goroutines only ever push onto the queue, so a slow client can't stall them.
+type outboundQueue struct {
+	conn      net.Conn
+	connMutex *sync.Mutex
+	packets   chan outboundFrame
+	done      chan struct{}
+	state     int32
+	stopOnce  sync.Once
+}
+
+// Creates a new outbound queue for a connection
+func newOutboundQueue(conn net.Conn, connMutex *sync.Mutex) *outboundQueue {
+	return &outboundQueue{
+		conn:      conn,
+		connMutex: connMutex,
+		packets:   make(chan outboundFrame, getOutboundQueueSize()),
+		done:      make(chan struct{}),
+		state:     outboundStateIdle,
+	}
+}
+
+// Starts the writer goroutine for the queue
+func (q *outboundQueue) start() {
+	if !atomic.CompareAndSwapInt32(&q.state, outboundStateIdle, outboundStateRunning) {
+		return
+	}
+
+	go q.run()
+}
+
+// Stops the writer goroutine. Packets that are already queued are still written.
+func (q *outboundQueue) stop() {
+	q.stopOnce.Do(func() {
+		atomic.StoreInt32(&q.state, outboundStateStopped)
+		close(q.done)
+	})
+}
+
+// Returns if the writer goroutine is accepting packets
+func (q *outboundQueue) isRunning() bool {
+	return atomic.LoadInt32(&q.state) == outboundStateRunning
+}
+
+// Returns if the queue was stopped, after which no more packets are written
+func (q *outboundQueue) isStopped() bool {
+	return atomic.LoadInt32(&q.state) == outboundStateStopped
+}
+
+// Returns the amount of packets waiting to be written
+func (q *outboundQueue) depth() int {
+	return len(q.packets)
+}
+
+// Pushes a packet onto the queue. Returns false if the queue is full or stopped.
+func (q *outboundQueue) push(frame outboundFrame) bool {
+	if !q.isRunning() {
+		return false
+	}
+
+	select {
+	case q.packets <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+// Writes queued packets to the connection until the queue is stopped or the connection fails
+func (q *outboundQueue) run() {
+	for {
+		select {
+		case frame := <-q.packets:
+			if err := q.write(frame); err != nil {
+				q.stop()
+				_ = q.conn.Close()
+				return
+			}
+		case <-q.done:
+			q.flush()
+			return
+		}
+	}
+}
+
+// Writes whatever is left in the queue without waiting for more packets
+func (q *outboundQueue) flush() {
+	for {
+		select {
+		case frame := <-q.packets:
+			if err := q.write(frame); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Writes a single packet to the connection
+func (q *outboundQueue) write(frame outboundFrame) error {
+	q.connMutex.Lock()
+	defer q.connMutex.Unlock()
+
+	_ = q.conn.SetWriteDeadline(time.Now().Add(outboundWriteTimeout))
+	return frame.writeTo(q.conn)
+}
+
+// GetOutboundQueueStats Returns the current outbound queue depth of all online users
+func GetOutboundQueueStats() OutboundQueueStats {
+	stats := OutboundQueueStats{
+		Dropped:     atomic.LoadInt64(&outboundDropped),
+		Disconnects: atomic.LoadInt64(&outboundDisconnects),
+	}
+
+	for _, user := range GetOnlineUsers() {
+		if user.outbound == nil {
+			continue
+		}
+
+		depth := user.outbound.depth()
+
+		stats.Users++
+		stats.TotalDepth += depth
+
+		if depth > stats.MaxDepth {
+			stats.MaxDepth = depth
+		}
+	}
+
+	return stats
+}
+
+// UpdateOutboundQueueMetrics Exports the current outbound queue depth of all online users
+func UpdateOutboundQueueMetrics() {
+	stats := GetOutboundQueueStats()
+
+	metrics.OutboundQueueDepth.Set(float64(stats.TotalDepth))
+	metrics.OutboundQueueMaxDepth.Set(float64(stats.MaxDepth))
+}
+
+// Returns the configured capacity of each outbound queue
+func getOutboundQueueSize() int {
+	settings := config.Get()
+
+	if settings == nil || settings.Server.OutboundQueueSize <= 0 {
+		return defaultOutboundQueueSize
+	}
+
+	return settings.Server.OutboundQueueSize
+}
+
+// Returns the configured policy for users whose outbound queue is full
+func getOutboundOverflowPolicy() OutboundOverflowPolicy {
+	settings := config.Get()
+
+	if settings == nil || settings.Server.OutboundOverflowPolicy == "" {
+		return OutboundOverflowDisconnect
+	}
+
+	return OutboundOverflowPolicy(settings.Server.OutboundOverflowPolicy)
+}
+
+// Applies the overflow policy to a user whose queue is full
+func handleOutboundOverflow(user *User) {
+	switch getOutboundOverflowPolicy() {
+	case OutboundOverflowDrop:
+		atomic.AddInt64(&outboundDropped, 1)
+	default:
+		// Only the first overflow closes the connection, the rest of the packets are dropped until the user is removed.
+		if !user.outbound.isRunning() {
+			atomic.AddInt64(&outboundDropped, 1)
+			return
+		}
+
+		atomic.AddInt64(&outboundDisconnects, 1)
+		user.outbound.stop()
+		_ = user.Conn.Close()
+
+		log.Printf("[%v #%v] Disconnected due to a full outbound queue (%v packets)\n", user.Info.Username, user.Info.Id, user.outbound.depth())
+	}
+}
@@ -0,0 +1,85 @@
+package sessions
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"github.com/gobwas/ws/wsutil"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOutboundQueueWritesPackets(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	user := NewUser(server, &db.User{Id: 1, Username: "User #1"})
+	user.startOutbound()
+	defer user.stopOutbound()
+
+	SendPacketToUser(packets.NewServerPing(), user)
+
+	data, err := wsutil.ReadServerText(client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var packet packets.Packet
+
+	if err := json.Unmarshal(data, &packet); err != nil {
+		t.Fatal(err)
+	}
+
+	if packet.Id != packets.PacketIdServerPing {
+		t.Fatalf("expected packet id %v, got %v", packets.PacketIdServerPing, packet.Id)
+	}
+}
+
+func TestOutboundQueueOverflowDisconnects(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	user := NewUser(server, &db.User{Id: 1, Username: "User #1"})
+	user.startOutbound()
+
+	disconnects := atomic.LoadInt64(&outboundDisconnects)
+
+	// Nobody reads from the client, so the writer blocks and the queue eventually fills up.
+	for i := 0; i < defaultOutboundQueueSize+10; i++ {
+		SendPacketToUser(packets.NewServerPing(), user)
+	}
+
+	if user.outbound.isRunning() {
+		t.Fatal("expected outbound queue to be stopped after overflowing")
+	}
+
+	if atomic.LoadInt64(&outboundDisconnects) != disconnects+1 {
+		t.Fatal("expected the user to be disconnected exactly once")
+	}
+}
+
+func TestOutboundQueueDropsPacketsAfterStopping(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	user := NewUser(server, &db.User{Id: 1, Username: "User #1"})
+	user.startOutbound()
+	user.stopOutbound()
+
+	done := make(chan struct{})
+
+	// Nobody reads from the client, so writing to the connection directly would block forever.
+	go func() {
+		SendPacketToUser(packets.NewServerPing(), user)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the packet to be dropped instead of written")
+	}
+}
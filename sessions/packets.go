@@ -1,7 +1,8 @@
 package sessions
 
 import (
-	"encoding/json"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/packets"
 	"github.com/gobwas/ws/wsutil"
 	"net"
 )
@@ -13,40 +14,48 @@ func SendPacketToConnection(data interface{}, conn net.Conn) {
 	}
 
 	user := GetUserByConnection(conn)
+
 	if user != nil {
-		user.ConnMutex.Lock()
-		defer user.ConnMutex.Unlock()
+		SendPacketToUser(data, user)
+		return
 	}
 
-	j, err := json.Marshal(data)
+	j, err := encodePacket(data, packets.EncodingJSON)
 
 	if err != nil {
 		return
 	}
 
-	err = wsutil.WriteServerText(conn, j)
+	metrics.PacketsSent.WithLabelValues(metrics.PacketLabel(getPacketId(data))).Inc()
+	_ = wsutil.WriteServerText(conn, j)
+}
+
+// SendPacketToUser Sends a packet to a given user
+func SendPacketToUser(data interface{}, user *User) {
+	if user.Conn == nil {
+		return
+	}
+
+	frame, err := encodeFrame(data, user.GetEncoding(), user.GetCompression())
 
 	if err != nil {
 		return
 	}
-}
 
-// SendPacketToUser Sends a packet to a given user
-func SendPacketToUser(data interface{}, user *User) {
-	SendPacketToConnection(data, user.Conn)
-	return
+	metrics.PacketsSent.WithLabelValues(metrics.PacketLabel(getPacketId(data))).Inc()
+	user.sendPacket(frame)
 }
 
-// SendPacketToUsers Sends a packet to a list of users
+// SendPacketToUsers Sends a packet to a list of users. The packet is only serialized once.
 func SendPacketToUsers(data interface{}, users ...*User) {
+	data = prepareForBroadcast(data)
+
 	for _, user := range users {
 		SendPacketToUser(data, user)
 	}
 }
 
-// SendPacketToAllUsers Sends a packet to every online user
+// SendPacketToAllUsers Sends a packet to every online user. The packet is only serialized once.
 func SendPacketToAllUsers(data interface{}) {
-	for _, user := range GetOnlineUsers() {
-		SendPacketToUser(data, user)
-	}
+	SendPacketToUsers(data, GetOnlineUsers()...)
 }
@@ -0,0 +1,122 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"net"
+	"testing"
+	"time"
+)
+
+const benchmarkUserCount = 5_000
+
+// A connection that discards everything written to it
+type discardConn struct{}
+
+func (c *discardConn) Read(b []byte) (int, error)         { select {} }
+func (c *discardConn) Write(b []byte) (int, error)        { return len(b), nil }
+func (c *discardConn) Close() error                       { return nil }
+func (c *discardConn) LocalAddr() net.Addr                { return nil }
+func (c *discardConn) RemoteAddr() net.Addr               { return nil }
+func (c *discardConn) SetDeadline(t time.Time) error      { return nil }
+func (c *discardConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *discardConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// Adds users with discarding connections to the session maps without touching redis
+func addBenchmarkUsers(b *testing.B, count int) []*User {
+	users := make([]*User, 0, count)
+
+	for i := 0; i < count; i++ {
+		user := NewUser(&discardConn{}, &db.User{Id: 1_000_000 + i, Username: "Benchmark"})
+		addUserToMaps(user)
+		user.startOutbound()
+		users = append(users, user)
+	}
+
+	b.Cleanup(func() {
+		for _, user := range users {
+			removeUserFromMaps(user)
+			user.stopOutbound()
+		}
+	})
+
+	return users
+}
+
+// Returns a lobby game info packet, which is one of the larger packets that get broadcast
+func newBenchmarkPacket() interface{} {
+	game := &objects.MultiplayerGame{
+		Id:       1,
+		GameId:   "3b1b5ea4c5c5e7d2bd1d9e9f3f1f5d6c",
+		Name:     "Benchmark Game",
+		MapName:  "Camellia - Exit This Earth's Atomosphere [Planetary 200MB]",
+		MapMD5:   "d41d8cd98f00b204e9800998ecf8427e",
+		HostId:   1,
+		MapId:    1,
+		MapsetId: 1,
+	}
+
+	game.SetDefaults()
+
+	for i := 0; i < 16; i++ {
+		game.PlayerIds = append(game.PlayerIds, i)
+		game.PlayerModifiers = append(game.PlayerModifiers, &objects.MultiplayerGamePlayerMods{Id: i, Modifiers: common.ModMirror})
+		game.PlayerWins = append(game.PlayerWins, &objects.MultiplayerGamePlayerWins{Id: i})
+	}
+
+	return packets.NewServerMultiplayerGameInfo(game)
+}
+
+// Serializes the packet once per user, which is how broadcasts used to work
+func BenchmarkBroadcastPerUser(b *testing.B) {
+	users := addBenchmarkUsers(b, benchmarkUserCount)
+	packet := newBenchmarkPacket()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, user := range users {
+			SendPacketToUser(packet, user)
+		}
+	}
+}
+
+// Serializes the packet once for all users
+func BenchmarkBroadcastPrepared(b *testing.B) {
+	users := addBenchmarkUsers(b, benchmarkUserCount)
+	packet := newBenchmarkPacket()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		SendPacketToUsers(packet, users...)
+	}
+}
+
+func TestPreparedPacketMatchesSinglePacket(t *testing.T) {
+	packet := newBenchmarkPacket()
+
+	single, err := encodePacket(packet, packets.EncodingJSON)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prepared, err := NewPreparedPacket(packet)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := encodePacket(prepared, packets.EncodingJSON)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(single) != string(encoded) {
+		t.Fatalf("expected prepared packet to be identical to the single packet")
+	}
+}
@@ -0,0 +1,52 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/objects"
+	"time"
+)
+
+// Adds the time spent in the user's current status to their totals. Must be called with the user's mutex held,
+// before their status is changed.
+func (u *User) addStatusTime() {
+	now := time.Now()
+
+	u.statusTimes[u.status.Status] += now.Sub(u.statusSetAt)
+	u.statusSetAt = now
+}
+
+// GetSessionStatusTimes Returns how long the user has spent in each status during this session
+func (u *User) GetSessionStatusTimes() map[objects.ClientStatusType]time.Duration {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	times := make(map[objects.ClientStatusType]time.Duration, len(u.statusTimes)+1)
+
+	for status, duration := range u.statusTimes {
+		times[status] = duration
+	}
+
+	times[u.status.Status] += time.Since(u.statusSetAt)
+	return times
+}
+
+// SetPreviousStatusTimes Sets how long the user spent in each status during their previous sessions
+func (u *User) SetPreviousStatusTimes(times map[objects.ClientStatusType]time.Duration) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.previousStatusTimes = times
+}
+
+// GetTotalStatusTimes Returns how long the user has spent in each status across all of their sessions
+func (u *User) GetTotalStatusTimes() map[objects.ClientStatusType]time.Duration {
+	times := u.GetSessionStatusTimes()
+
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	for status, duration := range u.previousStatusTimes {
+		times[status] += duration
+	}
+
+	return times
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"testing"
+	"time"
+)
+
+func TestStatusTimes(t *testing.T) {
+	user := NewUser(nil, &db.User{Id: 1, Username: "User #1", UserGroups: common.UserGroupNormal})
+
+	user.Mutex.Lock()
+	user.statusSetAt = time.Now().Add(-2 * time.Minute)
+	user.addStatusTime()
+	user.status = &objects.ClientStatus{Status: objects.ClientStatusPLaying}
+	user.statusSetAt = time.Now().Add(-time.Minute)
+	user.Mutex.Unlock()
+
+	user.SetPreviousStatusTimes(map[objects.ClientStatusType]time.Duration{objects.ClientStatusPLaying: time.Hour})
+
+	session := user.GetSessionStatusTimes()
+
+	if session[objects.ClientStatusInMenus].Round(time.Minute) != 2*time.Minute {
+		t.Fatalf("expected 2 minutes in menus, got %v", session[objects.ClientStatusInMenus])
+	}
+
+	if session[objects.ClientStatusPLaying].Round(time.Minute) != time.Minute {
+		t.Fatalf("expected the current status to be included, got %v", session[objects.ClientStatusPLaying])
+	}
+
+	if total := user.GetTotalStatusTimes()[objects.ClientStatusPLaying].Round(time.Minute); total != time.Hour+time.Minute {
+		t.Fatalf("expected previous sessions to be included, got %v", total)
+	}
+}
+
+func TestRecordActivitySample(t *testing.T) {
+	sample := RecordActivitySample()
+	history := GetActivityHistory()
+
+	if len(history) == 0 || history[len(history)-1] != sample {
+		t.Fatal("expected the sample to be added to the history")
+	}
+}
@@ -0,0 +1,101 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/packets"
+	"sync"
+)
+
+// PreparedPacket A packet that is serialized once and can be written to many connections.
+// Use this when broadcasting the same packet to multiple users.
+type PreparedPacket struct {
+	packet     interface{}
+	mutex      *sync.Mutex
+	encoded    map[packets.Encoding][]byte
+	compressed map[packets.Encoding][]byte
+}
+
+// NewPreparedPacket Serializes a packet so it can be sent to many users.
+// The packet is serialized as json right away, other encodings are serialized the first time they're needed.
+func NewPreparedPacket(data interface{}) (*PreparedPacket, error) {
+	j, err := packets.EncodingJSON.Marshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedPacket{
+		packet:     data,
+		mutex:      &sync.Mutex{},
+		encoded:    map[packets.Encoding][]byte{packets.EncodingJSON: j},
+		compressed: map[packets.Encoding][]byte{},
+	}, nil
+}
+
+// Returns the packet serialized with a given encoding
+func (p *PreparedPacket) encode(encoding packets.Encoding) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if data, ok := p.encoded[encoding]; ok {
+		return data, nil
+	}
+
+	data, err := encoding.Marshal(p.packet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.encoded[encoding] = data
+	return data, nil
+}
+
+// Returns the packet serialized with a given encoding and compressed with permessage-deflate
+func (p *PreparedPacket) compress(encoding packets.Encoding) ([]byte, error) {
+	data, err := p.encode(encoding)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if compressed, ok := p.compressed[encoding]; ok {
+		return compressed, nil
+	}
+
+	compressed, err := compress(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.compressed[encoding] = compressed
+	return compressed, nil
+}
+
+// Returns the serialized version of a packet. Prepared packets are only serialized once per encoding.
+func encodePacket(data interface{}, encoding packets.Encoding) ([]byte, error) {
+	if prepared, ok := data.(*PreparedPacket); ok {
+		return prepared.encode(encoding)
+	}
+
+	return encoding.Marshal(data)
+}
+
+// Prepares a packet for a broadcast. Packets that fail to serialize are returned as-is,
+// so the error surfaces the same way it would for a single user.
+func prepareForBroadcast(data interface{}) interface{} {
+	if _, ok := data.(*PreparedPacket); ok {
+		return data
+	}
+
+	prepared, err := NewPreparedPacket(data)
+
+	if err != nil {
+		return data
+	}
+
+	return prepared
+}
@@ -0,0 +1,83 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/utils"
+)
+
+// GetPresence Returns who is able to see that the user is online
+func (u *User) GetPresence() objects.Presence {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.presence
+}
+
+// SetPresence Sets who is able to see that the user is online
+func (u *User) SetPresence(presence objects.Presence) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.presence = presence
+}
+
+// CanSeeOnline Returns if the viewer is able to see that a user is online.
+// Users who appear offline can only be seen by themselves and their friends.
+func CanSeeOnline(viewer *User, user *User) bool {
+	if viewer == nil || viewer == user {
+		return true
+	}
+
+	if user.GetPresence() != objects.PresenceAppearOffline {
+		return true
+	}
+
+	return user.IsFriendsWith(viewer.Info.Id)
+}
+
+// CanSeeStatus Returns if the viewer is able to see what a user is currently doing
+func CanSeeStatus(viewer *User, user *User) bool {
+	if viewer == nil || viewer == user {
+		return true
+	}
+
+	if user.GetPresence() == objects.PresenceEveryone {
+		return true
+	}
+
+	return user.IsFriendsWith(viewer.Info.Id)
+}
+
+// Pushes the user's status to their subscribers and online friends who are allowed to see it.
+// Spectators receive the status separately, so they're skipped.
+func (u *User) pushClientStatus() {
+	spectators := u.GetSpectators()
+	recipients := make([]*User, 0)
+
+	addRecipient := func(recipient *User) {
+		if recipient == nil || utils.Includes(spectators, recipient) || utils.Includes(recipients, recipient) {
+			return
+		}
+
+		if !CanSeeStatus(recipient, u) {
+			return
+		}
+
+		recipients = append(recipients, recipient)
+	}
+
+	for _, subscriber := range getStatusSubscribers(u.Info.Id) {
+		addRecipient(subscriber)
+	}
+
+	for _, id := range u.GetFriends() {
+		addRecipient(GetUserById(id))
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	SendPacketToUsers(packets.NewServerUserStatusSingle(u.Info.Id, u.GetClientStatus()), recipients...)
+}
@@ -0,0 +1,40 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"testing"
+)
+
+func TestPresenceVisibility(t *testing.T) {
+	user := NewUser(nil, &db.User{Id: 1, Username: "User #1", UserGroups: common.UserGroupNormal})
+	friend := NewUser(nil, &db.User{Id: 2, Username: "User #2", UserGroups: common.UserGroupNormal})
+	stranger := NewUser(nil, &db.User{Id: 3, Username: "User #3", UserGroups: common.UserGroupNormal})
+
+	user.SetFriends([]int{2})
+
+	if !CanSeeOnline(stranger, user) || !CanSeeStatus(stranger, user) {
+		t.Fatal("expected everyone to see the user by default")
+	}
+
+	user.SetPresence(objects.PresenceFriendsOnlyStatus)
+
+	if !CanSeeOnline(stranger, user) || CanSeeStatus(stranger, user) {
+		t.Fatal("expected strangers to see the user online without their status")
+	}
+
+	if !CanSeeStatus(friend, user) {
+		t.Fatal("expected friends to see the user's status")
+	}
+
+	user.SetPresence(objects.PresenceAppearOffline)
+
+	if CanSeeOnline(stranger, user) || CanSeeStatus(stranger, user) {
+		t.Fatal("expected the user to appear offline to strangers")
+	}
+
+	if !CanSeeOnline(friend, user) || !CanSeeOnline(user, user) {
+		t.Fatal("expected the user to be visible to friends and themselves")
+	}
+}
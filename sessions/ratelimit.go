@@ -0,0 +1,128 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/utils"
+	"sync"
+	"time"
+)
+
+// RateLimitResult The outcome of checking a packet against a user's rate limits
+type RateLimitResult int
+
+const (
+	RateLimitAllowed RateLimitResult = iota
+	RateLimitDropped
+	RateLimitDisconnect
+)
+
+const (
+	defaultRateLimitDisconnectThreshold = 50
+	rateLimitViolationWindow            = time.Minute
+)
+
+// A limit for a single packet type
+type packetRateLimit struct {
+	burst     int
+	perSecond float64
+}
+
+// The limits that are used for packets that aren't configured
+var defaultPacketRateLimits = map[packets.PacketId]packetRateLimit{
+	packets.PacketIdClientRequestUserInfo:        {burst: 20, perSecond: 5},
+	packets.PacketIdClientUserStatusSubscription: {burst: 20, perSecond: 5},
+	packets.PacketIdClientSearchUsers:            {burst: 5, perSecond: 1},
+	packets.PacketIdClientRequestRecentPlayers:   {burst: 3, perSecond: 0.2},
+	packets.PacketIdClientRequestChatHistory:     {burst: 5, perSecond: 1},
+	packets.PacketIdClientCreateGame:             {burst: 3, perSecond: 0.2},
+	packets.PacketIdClientFriendship:             {burst: 5, perSecond: 0.5},
+	packets.PacketIdClientBlockUser:              {burst: 5, perSecond: 0.5},
+	packets.PacketIdClientChangePresence:         {burst: 5, perSecond: 0.5},
+	packets.PacketIdClientInviteToGame:           {burst: 5, perSecond: 1},
+}
+
+// A token bucket along with the limit it was created from
+type rateLimitBucket struct {
+	limit  packetRateLimit
+	bucket *utils.TokenBucket
+}
+
+// Keeps track of the token buckets and violations of a single user
+type rateLimiter struct {
+	buckets          map[packets.PacketId]*rateLimitBucket
+	violations       int
+	violationsWindow time.Time
+	mutex            *sync.Mutex
+}
+
+// Creates a new rate limiter for a user
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: map[packets.PacketId]*rateLimitBucket{},
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Checks if a packet is allowed. Dropped packets count as violations, and too many violations
+// within a minute escalate to a disconnect.
+func (r *rateLimiter) check(id packets.PacketId) RateLimitResult {
+	limit, ok := getPacketRateLimit(id)
+
+	if !ok {
+		return RateLimitAllowed
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// The bucket is recreated when the limit changes, so reloaded limits apply to users that are already online
+	bucket, ok := r.buckets[id]
+
+	if !ok || bucket.limit != limit {
+		bucket = &rateLimitBucket{limit: limit, bucket: utils.NewTokenBucket(limit.burst, limit.perSecond)}
+		r.buckets[id] = bucket
+	}
+
+	if bucket.bucket.Take() {
+		return RateLimitAllowed
+	}
+
+	if time.Since(r.violationsWindow) > rateLimitViolationWindow {
+		r.violations = 0
+		r.violationsWindow = time.Now()
+	}
+
+	r.violations++
+
+	if r.violations >= getRateLimitDisconnectThreshold() {
+		return RateLimitDisconnect
+	}
+
+	return RateLimitDropped
+}
+
+// Returns the rate limit for a packet type. Configured limits take precedence over the defaults.
+func getPacketRateLimit(id packets.PacketId) (packetRateLimit, bool) {
+	if settings := config.Get(); settings != nil {
+		for _, limit := range settings.RateLimits.Packets {
+			if packets.PacketId(limit.Id) == id {
+				return packetRateLimit{burst: limit.Burst, perSecond: limit.PerSecond}, true
+			}
+		}
+	}
+
+	limit, ok := defaultPacketRateLimits[id]
+	return limit, ok
+}
+
+// Returns the amount of dropped packets within a minute that gets a user disconnected
+func getRateLimitDisconnectThreshold() int {
+	settings := config.Get()
+
+	if settings == nil || settings.RateLimits.DisconnectThreshold <= 0 {
+		return defaultRateLimitDisconnectThreshold
+	}
+
+	return settings.RateLimits.DisconnectThreshold
+}
@@ -0,0 +1,73 @@
+package sessions
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"fmt"
+	"testing"
+)
+
+func TestRateLimiterEscalates(t *testing.T) {
+	limiter := newRateLimiter()
+	limit := defaultPacketRateLimits[packets.PacketIdClientCreateGame]
+
+	for i := 0; i < limit.burst; i++ {
+		if result := limiter.check(packets.PacketIdClientCreateGame); result != RateLimitAllowed {
+			t.Fatalf("expected packet #%v to be allowed, got %v", i+1, result)
+		}
+	}
+
+	if result := limiter.check(packets.PacketIdClientCreateGame); result != RateLimitDropped {
+		t.Fatalf("expected packet to be dropped after the burst, got %v", result)
+	}
+
+	var result RateLimitResult
+
+	for i := 1; i < defaultRateLimitDisconnectThreshold; i++ {
+		result = limiter.check(packets.PacketIdClientCreateGame)
+	}
+
+	if result != RateLimitDisconnect {
+		t.Fatalf("expected disconnect after %v violations, got %v", defaultRateLimitDisconnectThreshold, result)
+	}
+}
+
+func TestRateLimiterIgnoresUnlimitedPackets(t *testing.T) {
+	limiter := newRateLimiter()
+
+	for i := 0; i < 1000; i++ {
+		if limiter.check(packets.PacketIdClientSpectatorReplayFrames) != RateLimitAllowed {
+			t.Fatal("expected packets without a limit to always be allowed")
+		}
+	}
+}
+
+func TestRateLimiterPicksUpReloadedLimits(t *testing.T) {
+	previous := config.Get()
+	config.Set(config.Default())
+	defer func() { config.Set(previous) }()
+
+	limiter := newRateLimiter()
+
+	if limiter.check(packets.PacketIdClientCreateGame) != RateLimitAllowed {
+		t.Fatal("expected the first packet to be allowed")
+	}
+
+	reloaded := config.Default()
+	data := fmt.Sprintf(`{"rate_limits": {"packets": [{"id": %v, "burst": 1, "per_second": 0.001}]}}`, packets.PacketIdClientCreateGame)
+
+	if err := json.Unmarshal([]byte(data), reloaded); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Set(reloaded)
+
+	if limiter.check(packets.PacketIdClientCreateGame) != RateLimitAllowed {
+		t.Fatal("expected a fresh bucket with the reloaded limit")
+	}
+
+	if limiter.check(packets.PacketIdClientCreateGame) != RateLimitDropped {
+		t.Fatal("expected the reloaded burst to be used")
+	}
+}
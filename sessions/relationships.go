@@ -0,0 +1,117 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"sync"
+)
+
+// The users that a user has a relationship with, cached for the duration of their session
+type relationships struct {
+	friends map[int]struct{}
+	blocked map[int]struct{}
+	mutex   *sync.Mutex
+}
+
+// Creates an empty relationship cache
+func newRelationships() *relationships {
+	return &relationships{
+		friends: map[int]struct{}{},
+		blocked: map[int]struct{}{},
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// SetFriends Caches the ids of the user's friends
+func (u *User) SetFriends(userIds []int) {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	u.relationships.friends = make(map[int]struct{}, len(userIds))
+
+	for _, id := range userIds {
+		u.relationships.friends[id] = struct{}{}
+	}
+}
+
+// GetFriends Returns the ids of the user's friends
+func (u *User) GetFriends() []int {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	ids := make([]int, 0, len(u.relationships.friends))
+
+	for id := range u.relationships.friends {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// IsFriendsWith Returns if the user is friends with another user
+func (u *User) IsFriendsWith(userId int) bool {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	_, ok := u.relationships.friends[userId]
+	return ok
+}
+
+// SetBlockedUsers Caches the ids of the users that the user has blocked
+func (u *User) SetBlockedUsers(userIds []int) {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	u.relationships.blocked = make(map[int]struct{}, len(userIds))
+
+	for _, id := range userIds {
+		u.relationships.blocked[id] = struct{}{}
+	}
+}
+
+// GetBlockedUsers Returns the ids of the users that the user has blocked
+func (u *User) GetBlockedUsers() []int {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	ids := make([]int, 0, len(u.relationships.blocked))
+
+	for id := range u.relationships.blocked {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// SetUserBlocked Blocks or unblocks a user in the cache
+func (u *User) SetUserBlocked(userId int, blocked bool) {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	if blocked {
+		u.relationships.blocked[userId] = struct{}{}
+	} else {
+		delete(u.relationships.blocked, userId)
+	}
+}
+
+// HasBlocked Returns if the user has blocked another user
+func (u *User) HasBlocked(userId int) bool {
+	u.relationships.mutex.Lock()
+	defer u.relationships.mutex.Unlock()
+
+	_, ok := u.relationships.blocked[userId]
+	return ok
+}
+
+// IsBlockedBetween Returns if either user has blocked the other. Bots can't be blocked, so they're always able to reach users.
+func IsBlockedBetween(user *User, other *User) bool {
+	if user == nil || other == nil {
+		return false
+	}
+
+	if common.HasUserGroup(user.Info.UserGroups, common.UserGroupBot) || common.HasUserGroup(other.Info.UserGroups, common.UserGroupBot) {
+		return false
+	}
+
+	return user.HasBlocked(other.Info.Id) || other.HasBlocked(user.Info.Id)
+}
@@ -0,0 +1,33 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"testing"
+)
+
+func TestIsBlockedBetween(t *testing.T) {
+	user1 := NewUser(nil, &db.User{Id: 1, Username: "User #1", UserGroups: common.UserGroupNormal})
+	user2 := NewUser(nil, &db.User{Id: 2, Username: "User #2", UserGroups: common.UserGroupNormal})
+	bot := NewUser(nil, &db.User{Id: 3, Username: "Bot", UserGroups: common.UserGroupBot})
+
+	if IsBlockedBetween(user1, user2) {
+		t.Fatal("expected no block between the users")
+	}
+
+	user2.SetBlockedUsers([]int{1, 3})
+
+	if !IsBlockedBetween(user1, user2) || !IsBlockedBetween(user2, user1) {
+		t.Fatal("expected the block to apply in both directions")
+	}
+
+	if IsBlockedBetween(bot, user2) {
+		t.Fatal("expected bots to not be blockable")
+	}
+
+	user2.SetUserBlocked(1, false)
+
+	if IsBlockedBetween(user1, user2) {
+		t.Fatal("expected the user to be unblocked")
+	}
+}
@@ -1,6 +1,7 @@
 package sessions
 
 import (
+	"example.com/Quaver/Z/metrics"
 	"example.com/Quaver/Z/objects"
 	"net"
 	"strings"
@@ -30,6 +31,7 @@ var (
 // AddUser Adds a user session
 func AddUser(user *User) error {
 	addUserToMaps(user)
+	user.startOutbound()
 
 	err := UpdateRedisOnlineUserCount()
 
@@ -50,7 +52,9 @@ func AddUser(user *User) error {
 // RemoveUser Removes a user session
 func RemoveUser(user *User) error {
 	removeUserFromMaps(user)
+	user.stopOutbound()
 	user.StopSpectatingAll()
+	unsubscribeFromAllUserStatuses(user)
 
 	err := UpdateRedisOnlineUserCount()
 
@@ -105,14 +109,18 @@ func GetOnlineUserCount() int {
 	return len(userIdToUser)
 }
 
-// GetOnlineUserIds Returns a slice of user ids that are online
-func GetOnlineUserIds() []int {
+// GetOnlineUserIds Returns a slice of user ids that are online. Users that appear offline to the viewer are left out.
+func GetOnlineUserIds(viewer *User) []int {
 	userMutex.Lock()
 	defer userMutex.Unlock()
 
 	ids := make([]int, 0)
 
 	for _, user := range userIdToUser {
+		if !CanSeeOnline(viewer, user) {
+			continue
+		}
+
 		ids = append(ids, user.Info.Id)
 	}
 
@@ -133,14 +141,18 @@ func GetOnlineUsers() []*User {
 	return users
 }
 
-// GetSerializedOnlineUsers Returns a list of all online users serialized
-func GetSerializedOnlineUsers() []*objects.PacketUser {
+// GetSerializedOnlineUsers Returns a list of all online users serialized. Users that appear offline to the viewer are left out.
+func GetSerializedOnlineUsers(viewer *User) []*objects.PacketUser {
 	userMutex.Lock()
 	defer userMutex.Unlock()
 
 	users := make([]*objects.PacketUser, 0)
 
 	for _, user := range userIdToUser {
+		if !CanSeeOnline(viewer, user) {
+			continue
+		}
+
 		users = append(users, user.SerializeForPacket())
 	}
 
@@ -171,6 +183,7 @@ func addUserToMaps(user *User) {
 	userIdToUser[user.Info.Id] = user
 	usernameToUser[strings.ToLower(user.Info.Username)] = user
 	connToUser[user.Conn] = user
+	metrics.OnlineUsers.Set(float64(len(userIdToUser)))
 }
 
 // Removes a user from the maps that are used to look them up
@@ -181,6 +194,7 @@ func removeUserFromMaps(user *User) {
 	delete(userIdToUser, user.Info.Id)
 	delete(usernameToUser, strings.ToLower(user.Info.Username))
 	delete(connToUser, user.Conn)
+	metrics.OnlineUsers.Set(float64(len(userIdToUser)))
 }
 
 // Runs handlers that are used for spectator
@@ -9,7 +9,7 @@ import (
 func TestAddSpectator(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -32,7 +32,7 @@ func TestAddSpectator(t *testing.T) {
 func TestRemoveSpectator(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -52,7 +52,7 @@ func TestRemoveSpectator(t *testing.T) {
 func TestStopSpectatingAll(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -0,0 +1,115 @@
+package sessions
+
+import "sync"
+
+// The maximum amount of users a single user can be subscribed to
+const maxStatusSubscriptions = 1000
+
+var (
+	// mutex used for thread-safe access to status subscriptions
+	subscriptionMutex = &sync.Mutex{}
+
+	// The users that are subscribed to a user's status, with the key being the id of the user they're subscribed to.
+	// Subscriptions are stored by id, so users can subscribe to someone before they come online.
+	statusSubscribers = map[int]map[*User]struct{}{}
+
+	// The ids of the users that a user is subscribed to
+	statusSubscriptions = map[*User]map[int]struct{}{}
+)
+
+// SubscribeToUserStatuses Subscribes a user to the status updates of other users.
+// Returns the ids that weren't already subscribed to. Ids past the subscription limit are ignored, and so is everything
+// once the user's session has been removed, so a packet handled during logout can't leave subscriptions behind.
+func SubscribeToUserStatuses(user *User, userIds []int) []int {
+	subscriptionMutex.Lock()
+	defer subscriptionMutex.Unlock()
+
+	if user.statusSubscriptionsClosed {
+		return []int{}
+	}
+
+	subscriptions, ok := statusSubscriptions[user]
+
+	if !ok {
+		subscriptions = map[int]struct{}{}
+		statusSubscriptions[user] = subscriptions
+	}
+
+	added := make([]int, 0)
+
+	for _, id := range userIds {
+		if len(subscriptions) >= maxStatusSubscriptions {
+			break
+		}
+
+		if _, ok := subscriptions[id]; ok || id == user.Info.Id {
+			continue
+		}
+
+		if statusSubscribers[id] == nil {
+			statusSubscribers[id] = map[*User]struct{}{}
+		}
+
+		statusSubscribers[id][user] = struct{}{}
+		subscriptions[id] = struct{}{}
+		added = append(added, id)
+	}
+
+	return added
+}
+
+// UnsubscribeFromUserStatuses Unsubscribes a user from the status updates of other users
+func UnsubscribeFromUserStatuses(user *User, userIds []int) {
+	subscriptionMutex.Lock()
+	defer subscriptionMutex.Unlock()
+
+	for _, id := range userIds {
+		unsubscribeFromUserStatus(user, id)
+	}
+}
+
+// Removes every status subscription a user has and stops them from subscribing again
+func unsubscribeFromAllUserStatuses(user *User) {
+	subscriptionMutex.Lock()
+	defer subscriptionMutex.Unlock()
+
+	user.statusSubscriptionsClosed = true
+
+	for id := range statusSubscriptions[user] {
+		unsubscribeFromUserStatus(user, id)
+	}
+
+	delete(statusSubscriptions, user)
+}
+
+// Removes a single status subscription. The subscription mutex must be held.
+func unsubscribeFromUserStatus(user *User, userId int) {
+	delete(statusSubscriptions[user], userId)
+	delete(statusSubscribers[userId], user)
+
+	if len(statusSubscribers[userId]) == 0 {
+		delete(statusSubscribers, userId)
+	}
+}
+
+// Returns the amount of users a user is subscribed to
+func (u *User) getStatusSubscriptionCount() int {
+	subscriptionMutex.Lock()
+	defer subscriptionMutex.Unlock()
+
+	return len(statusSubscriptions[u])
+}
+
+// Returns the users that are subscribed to a user's status
+func getStatusSubscribers(userId int) []*User {
+	subscriptionMutex.Lock()
+	defer subscriptionMutex.Unlock()
+
+	subscribers := make([]*User, 0, len(statusSubscribers[userId]))
+
+	for subscriber := range statusSubscribers[userId] {
+		subscribers = append(subscribers, subscriber)
+	}
+
+	return subscribers
+}
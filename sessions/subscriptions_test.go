@@ -0,0 +1,53 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"testing"
+)
+
+func TestStatusSubscriptions(t *testing.T) {
+	user := NewUser(nil, &db.User{Id: 1, Username: "User #1", UserGroups: common.UserGroupNormal})
+	subscriber := NewUser(nil, &db.User{Id: 2, Username: "User #2", UserGroups: common.UserGroupNormal})
+
+	added := SubscribeToUserStatuses(subscriber, []int{1, 1, 2})
+
+	if len(added) != 1 || added[0] != 1 {
+		t.Fatalf("expected to only subscribe to user #1, got %v", added)
+	}
+
+	if len(SubscribeToUserStatuses(subscriber, []int{1})) != 0 {
+		t.Fatal("expected an existing subscription to not be added again")
+	}
+
+	if subscribers := getStatusSubscribers(user.Info.Id); len(subscribers) != 1 || subscribers[0] != subscriber {
+		t.Fatalf("expected user #2 to be subscribed to user #1, got %v", subscribers)
+	}
+
+	unsubscribeFromAllUserStatuses(subscriber)
+
+	if len(getStatusSubscribers(user.Info.Id)) != 0 || subscriber.getStatusSubscriptionCount() != 0 {
+		t.Fatal("expected every subscription to be removed")
+	}
+
+	if len(SubscribeToUserStatuses(subscriber, []int{1})) != 0 || len(getStatusSubscribers(user.Info.Id)) != 0 {
+		t.Fatal("expected a removed user to not be able to subscribe again")
+	}
+}
+
+func TestStatusSubscriptionLimit(t *testing.T) {
+	subscriber := NewUser(nil, &db.User{Id: 1, Username: "User #1", UserGroups: common.UserGroupNormal})
+	defer unsubscribeFromAllUserStatuses(subscriber)
+
+	ids := make([]int, 0, maxStatusSubscriptions+10)
+
+	for i := 0; i < maxStatusSubscriptions+10; i++ {
+		ids = append(ids, i+100)
+	}
+
+	SubscribeToUserStatuses(subscriber, ids)
+
+	if count := subscriber.getStatusSubscriptionCount(); count != maxStatusSubscriptions {
+		t.Fatalf("expected %v subscriptions, got %v", maxStatusSubscriptions, count)
+	}
+}
@@ -19,6 +19,15 @@ type User struct {
 
 	ConnMutex *sync.Mutex
 
+	// Packets waiting to be written to the connection
+	outbound *outboundQueue
+
+	// The encoding the user's client negotiated at login
+	encoding packets.Encoding
+
+	// If the user's connection negotiated permessage-deflate compression
+	compression bool
+
 	// The token used to identify the user for requests.
 	token string
 
@@ -46,6 +55,36 @@ type User struct {
 	// The current client status of the user
 	status *objects.ClientStatus
 
+	// The last time the user did something meaningful (changed status, chatted, played)
+	lastActivityTimestamp int64
+
+	// The status the user had before they were marked as away. Nil if the user isn't away.
+	statusBeforeAway *objects.ClientStatus
+
+	// The users who have been told that the user is away, so they're only told once
+	awayRepliedTo map[int]struct{}
+
+	// How long the user has spent in each status during this session, excluding their current status
+	statusTimes map[objects.ClientStatusType]time.Duration
+
+	// When the user's current status was set
+	statusSetAt time.Time
+
+	// How long the user spent in each status during their previous sessions
+	previousStatusTimes map[objects.ClientStatusType]time.Duration
+
+	// Limits how often the user can send each packet type
+	rateLimiter *rateLimiter
+
+	// If the user's session has been removed, so they can't subscribe to statuses anymore. Guarded by the subscription mutex.
+	statusSubscriptionsClosed bool
+
+	// The user's friends and the users they have blocked
+	relationships *relationships
+
+	// Who is able to see that the user is online
+	presence objects.Presence
+
 	// A count of the amount of messages the user has spammed in the past x amount of time. Used for muting purposes.
 	spammedChatMessages int
 
@@ -67,15 +106,19 @@ type User struct {
 
 // NewUser Creates a new user session struct object
 func NewUser(conn net.Conn, user *db.User) *User {
-	return &User{
-		Conn:              conn,
-		ConnMutex:         &sync.Mutex{},
-		token:             utils.GenerateRandomString(64),
-		Info:              user,
-		Mutex:             &sync.Mutex{},
-		stats:             map[common.Mode]*db.UserStats{},
-		lastPingTimestamp: time.Now().UnixMilli(),
-		lastPongTimestamp: time.Now().UnixMilli(),
+	u := &User{
+		Conn:                  conn,
+		ConnMutex:             &sync.Mutex{},
+		token:                 utils.GenerateRandomString(64),
+		Info:                  user,
+		Mutex:                 &sync.Mutex{},
+		stats:                 map[common.Mode]*db.UserStats{},
+		lastPingTimestamp:     time.Now().UnixMilli(),
+		lastPongTimestamp:     time.Now().UnixMilli(),
+		lastActivityTimestamp: time.Now().UnixMilli(),
+		statusTimes:           map[objects.ClientStatusType]time.Duration{},
+		statusSetAt:           time.Now(),
+		previousStatusTimes:   map[objects.ClientStatusType]time.Duration{},
 		status: &objects.ClientStatus{
 			Status:    0,
 			MapId:     -1,
@@ -84,10 +127,19 @@ func NewUser(conn net.Conn, user *db.User) *User {
 			Content:   "",
 			Modifiers: 0,
 		},
-		spectators: []*User{},
-		spectating: []*User{},
-		frames:     []*packets.ClientSpectatorReplayFrames{},
+		rateLimiter:   newRateLimiter(),
+		relationships: newRelationships(),
+		presence:      objects.Presence(user.Presence),
+		spectators:    []*User{},
+		spectating:    []*User{},
+		frames:        []*packets.ClientSpectatorReplayFrames{},
 	}
+
+	if conn != nil {
+		u.outbound = newOutboundQueue(conn, u.ConnMutex)
+	}
+
+	return u
 }
 
 // GetToken Returns the user token
@@ -95,6 +147,52 @@ func (u *User) GetToken() string {
 	return u.token
 }
 
+// GetOutboundQueueDepth Returns the amount of packets waiting to be written to the user's connection
+func (u *User) GetOutboundQueueDepth() int {
+	if u.outbound == nil {
+		return 0
+	}
+
+	return u.outbound.depth()
+}
+
+// GetEncoding Returns the encoding used for the user's packets
+func (u *User) GetEncoding() packets.Encoding {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.encoding
+}
+
+// SetEncoding Sets the encoding used for the user's packets
+func (u *User) SetEncoding(encoding packets.Encoding) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.encoding = encoding
+}
+
+// GetCompression Returns if packets sent to the user can be compressed
+func (u *User) GetCompression() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.compression
+}
+
+// SetCompression Sets if packets sent to the user can be compressed
+func (u *User) SetCompression(compression bool) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.compression = compression
+}
+
+// CheckRateLimit Checks if the user is allowed to send a packet of a given type
+func (u *User) CheckRateLimit(id packets.PacketId) RateLimitResult {
+	return u.rateLimiter.check(id)
+}
+
 // GetStats Retrieves the stats for the user
 func (u *User) GetStats() map[common.Mode]*db.UserStats {
 	u.Mutex.Lock()
@@ -204,17 +302,29 @@ func (u *User) GetClientStatus() *objects.ClientStatus {
 	return u.status
 }
 
-// SetClientStatus Sets the current user client status
+// SetClientStatus Sets the current user client status and pushes it to the users that are subscribed to it
+// Setting the status counts as activity, so users who were away no longer are.
 func (u *User) SetClientStatus(status *objects.ClientStatus) {
 	u.Mutex.Lock()
+	u.addStatusTime()
 	u.status = status
+	u.lastActivityTimestamp = time.Now().UnixMilli()
+	u.statusBeforeAway = nil
+	u.awayRepliedTo = nil
 	u.Mutex.Unlock()
 
+	u.onClientStatusChanged()
+}
+
+// Stores the user's new status and pushes it to the users that are subscribed to it
+func (u *User) onClientStatusChanged() {
 	err := addUserClientStatusToRedis(u)
 
 	if err != nil {
 		log.Println(err)
 	}
+
+	u.pushClientStatus()
 }
 
 // GetSpammedMessagesCount Gets the amount of messages the user has spammed
@@ -323,6 +433,10 @@ func (u *User) AddSpectator(spectator *User) {
 		return
 	}
 
+	if IsBlockedBetween(u, spectator) {
+		return
+	}
+
 	if utils.Includes(u.spectators, spectator) {
 		return
 	}
@@ -409,17 +523,57 @@ func (u *User) SerializeForPacket() *objects.PacketUser {
 	u.Mutex.Lock()
 	defer u.Mutex.Unlock()
 
+	return SerializeUserForPacket(u.Info)
+}
+
+// SerializeUserForPacket Serializes a user from the database to be used in a packet. Used for users that aren't online.
+func SerializeUserForPacket(user *db.User) *objects.PacketUser {
 	return &objects.PacketUser{
-		Id:              u.Info.Id,
-		SteamId:         u.Info.SteamId,
-		Username:        u.Info.Username,
-		UserGroups:      u.Info.UserGroups,
-		MuteEndTime:     u.Info.MuteEndTime,
-		Country:         u.Info.Country,
-		ClanId:          int(u.Info.ClanId.Int32),
-		ClanTag:         u.Info.ClanTag.String,
-		ClanAccentColor: u.Info.ClanAccentColor.String,
+		Id:              user.Id,
+		SteamId:         user.SteamId,
+		Username:        user.Username,
+		UserGroups:      user.UserGroups,
+		MuteEndTime:     user.MuteEndTime,
+		Country:         user.Country,
+		ClanId:          int(user.ClanId.Int32),
+		ClanTag:         user.ClanTag.String,
+		ClanAccentColor: user.ClanAccentColor.String,
+	}
+}
+
+// Queues an encoded packet to be written to the user's connection.
+// Packets sent before the user is logged in are written directly. Packets sent after the queue is stopped are dropped.
+func (u *User) sendPacket(frame outboundFrame) {
+	if u.outbound == nil || u.outbound.isStopped() {
+		return
+	}
+
+	if !u.outbound.isRunning() {
+		_ = u.outbound.write(frame)
+		return
 	}
+
+	if !u.outbound.push(frame) {
+		handleOutboundOverflow(u)
+	}
+}
+
+// Starts writing queued packets to the user's connection
+func (u *User) startOutbound() {
+	if u.outbound == nil {
+		return
+	}
+
+	u.outbound.start()
+}
+
+// Stops the user's outbound writer after flushing the packets that are already queued
+func (u *User) stopOutbound() {
+	if u.outbound == nil {
+		return
+	}
+
+	u.outbound.stop()
 }
 
 // Returns the Redis key for the user's session
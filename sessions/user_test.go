@@ -10,7 +10,7 @@ import (
 func TestPopulateUserStats(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket A rate limiter that allows bursts of up to capacity and refills at a steady rate
+type TokenBucket struct {
+	capacity   float64
+	refillRate float64
+	tokens     float64
+	lastRefill time.Time
+	mutex      *sync.Mutex
+}
+
+// NewTokenBucket Creates a full token bucket that refills perSecond tokens every second
+func NewTokenBucket(capacity int, perSecond float64) *TokenBucket {
+	return &TokenBucket{
+		capacity:   float64(capacity),
+		refillRate: perSecond,
+		tokens:     float64(capacity),
+		lastRefill: time.Now(),
+		mutex:      &sync.Mutex{},
+	}
+}
+
+// Take Takes a single token from the bucket. Returns false if the bucket is empty.
+func (b *TokenBucket) Take() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+
+	b.tokens = Clamp(b.tokens+now.Sub(b.lastRefill).Seconds()*b.refillRate, 0, b.capacity)
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
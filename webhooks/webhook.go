@@ -19,8 +19,8 @@ const antiCheatDescription string = "**❌ Anti-cheat Triggered!**"
 
 func Initialize() {
 	hooks := []string{
-		config.Instance.DiscordWebhooks.AntiCheat,
-		config.Instance.DiscordWebhooks.PrivateChat,
+		config.Get().DiscordWebhooks.AntiCheat,
+		config.Get().DiscordWebhooks.PrivateChat,
 	}
 
 	for _, hook := range hooks {
@@ -31,9 +31,9 @@ func Initialize() {
 		var err error
 
 		switch hook {
-		case config.Instance.DiscordWebhooks.AntiCheat:
+		case config.Get().DiscordWebhooks.AntiCheat:
 			AntiCheat, err = webhook.NewWithURL(hook)
-		case config.Instance.DiscordWebhooks.PrivateChat:
+		case config.Get().DiscordWebhooks.PrivateChat:
 			PrivateChat, err = webhook.NewWithURL(hook)
 		}
 
@@ -99,6 +99,33 @@ func SendAntiCheatLibraries(username string, userId int, url string, icon string
 	SendAntiCheat(username, userId, url, icon, "Detected Libraries", formatted)
 }
 
+// SendLoginFailureAlert Lets the anti-cheat team know that an ip address is repeatedly failing to log in
+func SendLoginFailureAlert(ip string, failures int, lastReason string) {
+	if AntiCheat == nil {
+		log.Printf("Cannot send nil Anti-Cheat webhook\n")
+		return
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetAuthor(ip, "", QuaverLogo).
+		SetDescription("**⚠️ Repeated Failed Logins**").
+		SetFields(discord.EmbedField{
+			Name:  "Failed Logins",
+			Value: fmt.Sprintf("%v failed logins from `%v`. The last one failed due to: `%v`", failures, ip, lastReason),
+		}).
+		SetThumbnail(QuaverLogo).
+		SetFooter("Quaver", QuaverLogo).
+		SetTimestamp(time.Now()).
+		SetColor(0xFFA500).
+		Build()
+
+	_, err := AntiCheat.CreateEmbeds([]discord.Embed{embed})
+
+	if err != nil {
+		log.Printf("Failed to send login failure webhook: %v\n", err)
+	}
+}
+
 // SendChatMessage Sends a chat message webhook to Discord
 func SendChatMessage(webhook webhook.Client, senderUsername string, senderProfileUrl string, senderAvatarUrl, receiverName string, message string) {
 	if webhook == nil {
@@ -8,7 +8,7 @@ import (
 func TestInitialize(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 
@@ -18,7 +18,7 @@ func TestInitialize(t *testing.T) {
 func TestSendAntiCheat(t *testing.T) {
 	_ = config.Load("../config.json")
 
-	if config.Instance == nil {
+	if config.Get() == nil {
 		return
 	}
 